@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
@@ -147,18 +148,19 @@ func (s Server) Start() error {
 	s.app.GET("/logout", s.Logout)
 
 	// console
-	console.New(s.queries).Register(s.app)
+	console.New(s.queries, s.BaseURL, s.Registration, s.Keys).Register(s.app)
 
 	// my account
-	me.New(s.queries, s.Key, s.BaseURL, s.fs).Register(s.app)
+	me.New(s.queries, s.BaseURL, s.fs).Register(s.app)
 
 	// oidc
 	oidcAPI, err := oidc.New(oidc.Config{
-		DB:        s.queries,
-		Key:       s.Key,
-		Providers: s.Providers,
-		BaseURL:   s.BaseURL,
-		FS:        s.fs,
+		DB:           s.queries,
+		Keys:         s.Keys,
+		Providers:    s.Providers,
+		BaseURL:      s.BaseURL,
+		FS:           s.fs,
+		Registration: s.Registration,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to setup OIDC APIs: %w", err)
@@ -168,5 +170,8 @@ func (s Server) Start() error {
 		return fmt.Errorf("failed to register OIDC APIs: %w", err)
 	}
 
+	// back-channel logout delivery
+	go oidcAPI.RunLogoutOutbox(context.Background())
+
 	return s.app.Start(fmt.Sprintf(":%d", s.Port))
 }
@@ -0,0 +1,339 @@
+package console
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/murtaza-u/ellipsis/api/apierr"
+	"github.com/murtaza-u/ellipsis/api/middleware"
+	"github.com/murtaza-u/ellipsis/api/render"
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+	"github.com/murtaza-u/ellipsis/view"
+	"github.com/murtaza-u/ellipsis/view/layout"
+	"github.com/murtaza-u/ellipsis/view/partial/console"
+
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+)
+
+func (a API) apisPage(c echo.Context) error {
+	apis, err := a.db.GetAPIResources(c.Request().Context())
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read api resources from db: %w", err),
+			layout.Base(
+				"Console - APIs | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+
+	var avatarURL string
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		avatarURL = ctx.AvatarURL
+	}
+
+	return render.Do(render.Params{
+		Ctx: c,
+		Component: layout.Base(
+			"Console - APIs | Ellipsis",
+			view.Console("/console/api", avatarURL, console.APIs(apis)),
+		),
+	})
+}
+
+func (a API) apiPage(c echo.Context) error {
+	id := c.Param("id")
+	if len(id) != 25 {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Console - API | Ellipsis",
+				view.Error(
+					"Invalid API ID",
+					http.StatusBadRequest,
+				),
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	api, err := a.db.GetAPIResource(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: layout.Base(
+					"Console - API | Ellipsis",
+					view.Error(
+						"API not found",
+						http.StatusNotFound,
+					),
+				),
+				Status: http.StatusNotFound,
+			})
+		}
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read api resource from db: %w", err),
+			layout.Base(
+				"Console - API | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+
+	var avatarURL string
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		avatarURL = ctx.AvatarURL
+	}
+
+	return render.Do(render.Params{
+		Ctx: c,
+		Component: layout.Base(
+			"Console - APIs | Ellipsis",
+			view.Console("/console/api", avatarURL, console.API(api)),
+		),
+	})
+}
+
+func (API) createAPIPage(c echo.Context) error {
+	var avatarURL string
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		avatarURL = ctx.AvatarURL
+	}
+
+	return render.Do(render.Params{
+		Ctx: c,
+		Component: layout.Base(
+			"Console - Create API | Ellipsis",
+			view.Console("/console/api", avatarURL, console.APICreate()),
+		),
+	})
+}
+
+func (a API) createAPI(c echo.Context) error {
+	params := new(console.APIParams)
+	if err := c.Bind(params); err != nil {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"Failed to parse form",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	v := newAPIValidator(*params)
+	params, errMap := v.Validate()
+	if err := a.checkAPIConflicts(c, *params, errMap); err != nil {
+		return err
+	}
+	if len(errMap) != 0 {
+		return render.Do(render.Params{
+			Ctx:       c,
+			Component: console.APICreateForm(*params, errMap),
+			Status:    http.StatusBadRequest,
+		})
+	}
+
+	id, err := util.GenerateRandom(25)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to generate random string: %w", err),
+			view.Error(
+				"Failed to generate API id",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	_, err = a.db.CreateAPIResource(
+		c.Request().Context(),
+		sqlc.CreateAPIResourceParams{
+			ID:              id,
+			Name:            params.Name,
+			Identifier:      params.Identifier,
+			Scopes:          nullString(params.Scopes),
+			TokenExpiration: int64(params.TokenExpiration),
+		},
+	)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to insert api resource into db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	// redirect to the newly created API
+	r := c.Response()
+	r.Header().Set("HX-Redirect", fmt.Sprintf("/console/api/%s", id))
+
+	// render empty template
+	h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusCreated))
+	return h.Component.Render(c.Request().Context(), r)
+}
+
+func (a API) updateAPI(c echo.Context) error {
+	params := new(console.APIParams)
+	if err := c.Bind(params); err != nil {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"Failed to parse form",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	if len(params.ID) != 25 {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"Invalid API id",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+	_, err := a.db.GetAPIResource(c.Request().Context(), params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: view.Error(
+					"API not found",
+					http.StatusNotFound,
+				),
+				Status: http.StatusNotFound,
+			})
+		}
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read api resource from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	v := newAPIValidator(*params)
+	params, errMap := v.Validate()
+	if err := a.checkAPIConflicts(c, *params, errMap); err != nil {
+		return err
+	}
+	if len(errMap) != 0 {
+		return render.Do(render.Params{
+			Ctx:       c,
+			Component: console.APIUpdateForm(*params, false, errMap),
+			Status:    http.StatusBadRequest,
+		})
+	}
+
+	err = a.db.UpdateAPIResource(
+		c.Request().Context(),
+		sqlc.UpdateAPIResourceParams{
+			ID:              params.ID,
+			Name:            params.Name,
+			Identifier:      params.Identifier,
+			Scopes:          nullString(params.Scopes),
+			TokenExpiration: int64(params.TokenExpiration),
+		},
+	)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to update api resource in db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	return render.Do(render.Params{
+		Ctx:       c,
+		Component: console.APIUpdateForm(*params, true, map[string]error{}),
+		Status:    http.StatusCreated,
+	})
+}
+
+// checkAPIConflicts records an error for the name and identifier if they
+// are already used by another API.
+func (a API) checkAPIConflicts(c echo.Context, p console.APIParams, errMap map[string]error) error {
+	ctx := c.Request().Context()
+	if errMap["name"] == nil {
+		api, err := a.db.GetAPIResourceByName(ctx, p.Name)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(
+				http.StatusInternalServerError,
+				fmt.Errorf("failed to read api resource by name: %w", err),
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			)
+		}
+		if err == nil && api.ID != p.ID {
+			errMap["name"] = errors.New("name already in use")
+		}
+	}
+	if errMap["identifier"] == nil {
+		api, err := a.db.GetAPIResourceByIdentifier(ctx, p.Identifier)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(
+				http.StatusInternalServerError,
+				fmt.Errorf("failed to read api resource by identifier: %w", err),
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			)
+		}
+		if err == nil && api.ID != p.ID {
+			errMap["identifier"] = errors.New("identifier already in use")
+		}
+	}
+	return nil
+}
+
+func (a API) deleteAPI(c echo.Context) error {
+	id := c.Param("id")
+	err := a.db.DeleteAPIResource(c.Request().Context(), id)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to delete api resource from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	// redirect to "/console/api"
+	r := c.Response()
+	r.Header().Set("HX-Redirect", "/console/api")
+
+	// render empty template
+	h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
+	return h.Component.Render(c.Request().Context(), r)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/murtaza-u/ellipsis/api/apierr"
 	"github.com/murtaza-u/ellipsis/api/middleware"
+	"github.com/murtaza-u/ellipsis/api/oidc"
 	"github.com/murtaza-u/ellipsis/api/render"
 	"github.com/murtaza-u/ellipsis/api/util"
 	"github.com/murtaza-u/ellipsis/internal/sqlc"
@@ -94,6 +95,51 @@ func (a API) appPage(c echo.Context) error {
 		)
 	}
 
+	logouts, err := a.db.GetBackchannelLogoutsForClientID(c.Request().Context(), id)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read back-channel logouts from db: %w", err),
+			layout.Base(
+				"Console - App | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+
+	policies, err := a.db.GetTokenExchangePoliciesForClientID(c.Request().Context(), id)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read token exchange policies from db: %w", err),
+			layout.Base(
+				"Console - App | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+
+	apis, err := a.db.GetAPIResources(c.Request().Context())
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read api resources from db: %w", err),
+			layout.Base(
+				"Console - App | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+
 	var avatarURL string
 	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
 		avatarURL = ctx.AvatarURL
@@ -103,7 +149,10 @@ func (a API) appPage(c echo.Context) error {
 		Ctx: c,
 		Component: layout.Base(
 			"Console - Apps | Ellipsis",
-			view.Console("/console/app", avatarURL, console.App(client)),
+			view.Console(
+				"/console/app", avatarURL,
+				console.App(client, logouts, policies, apis),
+			),
 		),
 	})
 }
@@ -135,7 +184,7 @@ func (a API) createApp(c echo.Context) error {
 			Status: http.StatusBadRequest,
 		})
 	}
-	v := newAppValidator(*params)
+	v := newAppValidator(*params, a.keys.Algs())
 	params, errMap := v.Validate()
 	if errMap["name"] == nil {
 		_, err := a.db.GetClientByName(c.Request().Context(), params.Name)
@@ -174,52 +223,27 @@ func (a API) createApp(c echo.Context) error {
 		)
 	}
 
-	secret, err := util.GenerateRandom(70)
-	if err != nil {
-		return apierr.New(
-			http.StatusInternalServerError,
-			fmt.Errorf("failed to generate random string: %w", err),
-			view.Error(
-				"Failed to generate client secret",
-				http.StatusInternalServerError,
-			),
-		)
-	}
-
-	hash, err := argon2id.CreateHash(secret, argon2id.DefaultParams)
-	if err != nil {
-		return apierr.New(
-			http.StatusInternalServerError,
-			fmt.Errorf("failed to create argon2id hash: %w", err),
-			view.Error(
-				"Failed to hash secret",
+	// public clients are not issued a secret
+	var secret string
+	var secretHash sql.NullString
+	if params.ClientType == oidc.ClientTypeConfidential {
+		secret, secretHash, err = newClientSecret()
+		if err != nil {
+			return apierr.New(
 				http.StatusInternalServerError,
-			),
-		)
-	}
-
-	var pictureUrl sql.NullString
-	if params.LogoURL != "" {
-		pictureUrl.Valid = true
-		pictureUrl.String = params.LogoURL
-	}
-
-	var backchannelLogoutURL sql.NullString
-	if params.BackchannelLogoutURL != "" {
-		backchannelLogoutURL.Valid = true
-		backchannelLogoutURL.String = params.BackchannelLogoutURL
+				err,
+				view.Error(
+					"Failed to generate client secret",
+					http.StatusInternalServerError,
+				),
+			)
+		}
 	}
 
-	_, err = a.db.CreateClient(c.Request().Context(), sqlc.CreateClientParams{
-		ID:                   id,
-		SecretHash:           hash,
-		Name:                 params.Name,
-		PictureUrl:           pictureUrl,
-		AuthCallbackUrls:     params.AuthCallbackURLs,
-		LogoutCallbackUrls:   params.LogoutCallbackURLs,
-		BackchannelLogoutUrl: backchannelLogoutURL,
-		TokenExpiration:      int64(params.IDTokenExpiration),
-	})
+	_, err = a.db.CreateClient(
+		c.Request().Context(),
+		createClientParams(id, secretHash, *params),
+	)
 	if err != nil {
 		return apierr.New(
 			http.StatusInternalServerError,
@@ -261,7 +285,7 @@ func (a API) updateApp(c echo.Context) error {
 			Status: http.StatusBadRequest,
 		})
 	}
-	_, err := a.db.GetClient(c.Request().Context(), params.ID)
+	client, err := a.db.GetClient(c.Request().Context(), params.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return render.Do(render.Params{
@@ -283,7 +307,10 @@ func (a API) updateApp(c echo.Context) error {
 		)
 	}
 
-	v := newAppValidator(*params)
+	// client type can not be changed once the app is created
+	params.ClientType = client.ClientType
+
+	v := newAppValidator(*params, a.keys.Algs())
 	params, errMap := v.Validate()
 	if errMap["name"] == nil {
 		_, err = a.db.GetClientByNameForUnmatchingID(
@@ -315,27 +342,10 @@ func (a API) updateApp(c echo.Context) error {
 		})
 	}
 
-	var pictureUrl sql.NullString
-	if params.LogoURL != "" {
-		pictureUrl.Valid = true
-		pictureUrl.String = params.LogoURL
-	}
-
-	var backchannelLogoutURL sql.NullString
-	if params.BackchannelLogoutURL != "" {
-		backchannelLogoutURL.Valid = true
-		backchannelLogoutURL.String = params.BackchannelLogoutURL
-	}
-
-	err = a.db.UpdateClient(c.Request().Context(), sqlc.UpdateClientParams{
-		ID:                   params.ID,
-		Name:                 params.Name,
-		PictureUrl:           pictureUrl,
-		AuthCallbackUrls:     params.AuthCallbackURLs,
-		LogoutCallbackUrls:   params.LogoutCallbackURLs,
-		BackchannelLogoutUrl: backchannelLogoutURL,
-		TokenExpiration:      int64(params.IDTokenExpiration),
-	})
+	err = a.db.UpdateClient(
+		c.Request().Context(),
+		updateClientParams(*params),
+	)
 	if err != nil {
 		return apierr.New(
 			http.StatusInternalServerError,
@@ -386,3 +396,68 @@ func (a API) deleteApp(c echo.Context) error {
 	h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
 	return h.Component.Render(c.Request().Context(), r)
 }
+
+// newClientSecret generates a client secret along with its argon2id hash.
+func newClientSecret() (string, sql.NullString, error) {
+	secret, err := util.GenerateRandom(70)
+	if err != nil {
+		return "", sql.NullString{}, fmt.Errorf("failed to generate random string: %w", err)
+	}
+	hash, err := argon2id.CreateHash(secret, argon2id.DefaultParams)
+	if err != nil {
+		return "", sql.NullString{}, fmt.Errorf("failed to create argon2id hash: %w", err)
+	}
+	return secret, sql.NullString{String: hash, Valid: true}, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func createClientParams(id string, secretHash sql.NullString, p console.AppParams) sqlc.CreateClientParams {
+	return sqlc.CreateClientParams{
+		ID:                                 id,
+		SecretHash:                         secretHash,
+		Name:                               p.Name,
+		PictureUrl:                         nullString(p.LogoURL),
+		AuthCallbackUrls:                   p.AuthCallbackURLs,
+		LogoutCallbackUrls:                 p.LogoutCallbackURLs,
+		BackchannelLogoutUrl:               nullString(p.BackchannelLogoutURL),
+		FrontchannelLogoutUri:              nullString(p.FrontchannelLogoutURI),
+		TokenExpiration:                    int64(p.IDTokenExpiration),
+		ClientType:                         p.ClientType,
+		AllowedScopes:                      nullString(p.AllowedScopes),
+		TokenEndpointAuthMethod:            p.TknEndpAuthMethod,
+		Jwks:                               nullString(p.JWKs),
+		JwksUri:                            nullString(p.JWKsURI),
+		RequireSignedRequestObject:         p.RequireSignedReqObj,
+		RequirePushedAuthorizationRequests: p.RequirePAR,
+		IDTokenSignedResponseAlg:           p.IDTknSignedRespAlg,
+		UserinfoSignedResponseAlg:          nullString(p.UserinfoSignedRespAlg),
+		AccessTokenFormat:                  p.AccessTknFormat,
+		RequireDpop:                        p.RequireDPoP,
+	}
+}
+
+func updateClientParams(p console.AppParams) sqlc.UpdateClientParams {
+	return sqlc.UpdateClientParams{
+		ID:                                 p.ID,
+		Name:                               p.Name,
+		PictureUrl:                         nullString(p.LogoURL),
+		AuthCallbackUrls:                   p.AuthCallbackURLs,
+		LogoutCallbackUrls:                 p.LogoutCallbackURLs,
+		BackchannelLogoutUrl:               nullString(p.BackchannelLogoutURL),
+		FrontchannelLogoutUri:              nullString(p.FrontchannelLogoutURI),
+		TokenExpiration:                    int64(p.IDTokenExpiration),
+		AllowedScopes:                      nullString(p.AllowedScopes),
+		TokenEndpointAuthMethod:            p.TknEndpAuthMethod,
+		Jwks:                               nullString(p.JWKs),
+		JwksUri:                            nullString(p.JWKsURI),
+		RequireSignedRequestObject:         p.RequireSignedReqObj,
+		RequirePushedAuthorizationRequests: p.RequirePAR,
+		IDTokenSignedResponseAlg:           p.IDTknSignedRespAlg,
+		UserinfoSignedResponseAlg:          nullString(p.UserinfoSignedRespAlg),
+		AccessTokenFormat:                  p.AccessTknFormat,
+		RequireDpop:                        p.RequireDPoP,
+	}
+}
@@ -2,18 +2,25 @@ package console
 
 import (
 	"github.com/murtaza-u/ellipsis/api/middleware"
+	"github.com/murtaza-u/ellipsis/internal/conf"
 	"github.com/murtaza-u/ellipsis/internal/sqlc"
 
 	"github.com/labstack/echo/v4"
 )
 
 type API struct {
-	db *sqlc.Queries
+	db           *sqlc.Queries
+	baseURL      string
+	registration conf.Registration
+	keys         conf.KeySet
 }
 
-func New(db *sqlc.Queries) API {
+func New(db *sqlc.Queries, baseURL string, registration conf.Registration, keys conf.KeySet) API {
 	return API{
-		db: db,
+		db:           db,
+		baseURL:      baseURL,
+		registration: registration,
+		keys:         keys,
 	}
 }
 
@@ -32,7 +39,25 @@ func (a API) Register(app *echo.Echo) {
 	grp.DELETE("/app/:id", a.deleteApp)
 	grp.GET("/app/create", a.createAppPage)
 	grp.POST("/app/create", a.createApp)
+	grp.POST("/app/:id/token-exchange", a.createTknExchangePolicy)
+	grp.DELETE("/app/:id/token-exchange/:policy", a.deleteTknExchangePolicy)
+
+	// api
+	grp.GET("/api", a.apisPage)
+	grp.GET("/api/:id", a.apiPage)
+	grp.PUT("/api/:id", a.updateAPI)
+	grp.DELETE("/api/:id", a.deleteAPI)
+	grp.GET("/api/create", a.createAPIPage)
+	grp.POST("/api/create", a.createAPI)
 
 	// user
 	grp.GET("/user", a.userPage)
+
+	// dynamic client registration
+	if a.registration.Enable {
+		app.POST("/oauth/register", a.registerClient)
+		app.GET("/oauth/register/:id", a.readRegisteredClient)
+		app.PUT("/oauth/register/:id", a.updateRegisteredClient)
+		app.DELETE("/oauth/register/:id", a.deleteRegisteredClient)
+	}
 }
@@ -0,0 +1,223 @@
+package console
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/murtaza-u/ellipsis/api/apierr"
+	"github.com/murtaza-u/ellipsis/api/oidc"
+	"github.com/murtaza-u/ellipsis/api/render"
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+	"github.com/murtaza-u/ellipsis/view"
+	"github.com/murtaza-u/ellipsis/view/partial/console"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (a API) createTknExchangePolicy(c echo.Context) error {
+	params := new(console.TknExchangePolicyParams)
+	if err := c.Bind(params); err != nil {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"Failed to parse form",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	ctx := c.Request().Context()
+
+	client, err := a.db.GetClient(ctx, params.ClientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: view.Error(
+					"App not found",
+					http.StatusNotFound,
+				),
+				Status: http.StatusNotFound,
+			})
+		}
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read client from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+	if client.ClientType != oidc.ClientTypeConfidential {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"Only confidential apps can exchange tokens",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	errMap := make(map[string]error)
+	params.Scopes = strings.Join(strings.Fields(params.Scopes), " ")
+	if len(params.Scopes) > 255 {
+		errMap["scopes"] = errors.New("value too long")
+	}
+
+	api, err := a.db.GetAPIResource(ctx, params.APIID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read api resource from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+	if err != nil {
+		errMap["api_id"] = errors.New("API not found")
+	} else {
+		apiScopes := strings.Fields(api.Scopes.String)
+		for _, s := range strings.Fields(params.Scopes) {
+			if !slices.Contains(apiScopes, s) {
+				errMap["scopes"] = fmt.Errorf("scope %q is not defined by the API", s)
+				break
+			}
+		}
+
+		_, err := a.db.GetTokenExchangePolicy(ctx, sqlc.GetTokenExchangePolicyParams{
+			ClientID:      client.ID,
+			ApiResourceID: api.ID,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return apierr.New(
+				http.StatusInternalServerError,
+				fmt.Errorf("failed to read token exchange policy from db: %w", err),
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			)
+		}
+		if err == nil {
+			errMap["api_id"] = errors.New("token exchange is already allowed for this API")
+		}
+	}
+	if len(errMap) != 0 {
+		return a.renderTknExchangePolicies(c, client.ID, *params, errMap, http.StatusBadRequest)
+	}
+
+	id, err := util.GenerateRandom(25)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to generate random string: %w", err),
+			view.Error(
+				"Failed to generate policy id",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	_, err = a.db.CreateTokenExchangePolicy(ctx, sqlc.CreateTokenExchangePolicyParams{
+		ID:            id,
+		ClientID:      client.ID,
+		ApiResourceID: api.ID,
+		Scopes:        nullString(params.Scopes),
+	})
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to insert token exchange policy into db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	return a.renderTknExchangePolicies(
+		c, client.ID,
+		console.TknExchangePolicyParams{},
+		map[string]error{},
+		http.StatusCreated,
+	)
+}
+
+func (a API) deleteTknExchangePolicy(c echo.Context) error {
+	clientID := c.Param("id")
+	err := a.db.DeleteTokenExchangePolicy(
+		c.Request().Context(),
+		sqlc.DeleteTokenExchangePolicyParams{
+			ID:       c.Param("policy"),
+			ClientID: clientID,
+		},
+	)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to delete token exchange policy from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	return a.renderTknExchangePolicies(
+		c, clientID,
+		console.TknExchangePolicyParams{},
+		map[string]error{},
+		http.StatusOK,
+	)
+}
+
+// renderTknExchangePolicies re-renders the token exchange section of an
+// app page.
+func (a API) renderTknExchangePolicies(
+	c echo.Context,
+	clientID string,
+	values console.TknExchangePolicyParams,
+	errMap map[string]error,
+	status int,
+) error {
+	ctx := c.Request().Context()
+
+	policies, err := a.db.GetTokenExchangePoliciesForClientID(ctx, clientID)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read token exchange policies from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+	apis, err := a.db.GetAPIResources(ctx)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read api resources from db: %w", err),
+			view.Error(
+				"Database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	return render.Do(render.Params{
+		Ctx:       c,
+		Component: console.TknExchangePolicies(clientID, policies, apis, values, errMap),
+		Status:    status,
+	})
+}
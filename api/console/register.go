@@ -0,0 +1,423 @@
+package console
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/api/oidc"
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+	"github.com/murtaza-u/ellipsis/view/partial/console"
+
+	"github.com/labstack/echo/v4"
+)
+
+// default id token expiration (in seconds) for registered clients
+const defaultTknExpiration = 28800
+
+var errInvalidRegistrationTkn = errors.New("invalid or missing access token")
+
+// client metadata as per RFC 7591 section 2
+type clientMetadata struct {
+	ClientName             string          `json:"client_name"`
+	RedirectURIs           []string        `json:"redirect_uris"`
+	PostLogoutRedirectURIs []string        `json:"post_logout_redirect_uris,omitempty"`
+	LogoURI                string          `json:"logo_uri,omitempty"`
+	BackchannelLogoutURI   string          `json:"backchannel_logout_uri,omitempty"`
+	FrontchannelLogoutURI  string          `json:"frontchannel_logout_uri,omitempty"`
+	TknEndpAuthMethod      string          `json:"token_endpoint_auth_method,omitempty"`
+	Scope                  string          `json:"scope,omitempty"`
+	JWKs                   json.RawMessage `json:"jwks,omitempty"`
+	JWKsURI                string          `json:"jwks_uri,omitempty"`
+	RequireSignedReqObj    bool            `json:"require_signed_request_object,omitempty"`
+	RequirePAR             bool            `json:"require_pushed_authorization_requests,omitempty"`
+	IDTknSignedRespAlg     string          `json:"id_token_signed_response_alg,omitempty"`
+	UserinfoSignedRespAlg  string          `json:"userinfo_signed_response_alg,omitempty"`
+	DPoPBoundAccessTkns    bool            `json:"dpop_bound_access_tokens,omitempty"`
+}
+
+type registrationReq struct {
+	clientMetadata
+	ClientID string `json:"client_id,omitempty"`
+}
+
+type registrationResp struct {
+	clientMetadata
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt      int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt int64  `json:"client_secret_expires_at"`
+	RegistrationAccessTkn string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI string `json:"registration_client_uri"`
+}
+
+type registrationErr struct {
+	Err     string `json:"error"`
+	ErrDesc string `json:"error_description,omitempty"`
+}
+
+// app form fields mapped to their client metadata counterpart
+var metadataFields = map[string]string{
+	"name":                                  "client_name",
+	"client_type":                           "token_endpoint_auth_method",
+	"logo_url":                              "logo_uri",
+	"backchannel_logout_url":                "backchannel_logout_uri",
+	"frontchannel_logout_uri":               "frontchannel_logout_uri",
+	"auth_callback_urls":                    "redirect_uris",
+	"logout_callback_urls":                  "post_logout_redirect_uris",
+	"id_token_expiration":                   "id_token_expiration",
+	"allowed_scopes":                        "scope",
+	"token_endpoint_auth_method":            "token_endpoint_auth_method",
+	"jwks":                                  "jwks",
+	"jwks_uri":                              "jwks_uri",
+	"require_signed_request_object":         "require_signed_request_object",
+	"require_pushed_authorization_requests": "require_pushed_authorization_requests",
+	"id_token_signed_response_alg":          "id_token_signed_response_alg",
+	"userinfo_signed_response_alg":          "userinfo_signed_response_alg",
+	"require_dpop":                          "dpop_bound_access_tokens",
+}
+
+// appParams converts client metadata into app params so that registered
+// clients go through the same validation as apps created from the
+// console.
+func (m clientMetadata) appParams() console.AppParams {
+	p := console.AppParams{
+		Name:                  m.ClientName,
+		ClientType:            oidc.ClientTypeConfidential,
+		LogoURL:               m.LogoURI,
+		AuthCallbackURLs:      strings.Join(m.RedirectURIs, ","),
+		LogoutCallbackURLs:    strings.Join(m.PostLogoutRedirectURIs, ","),
+		BackchannelLogoutURL:  m.BackchannelLogoutURI,
+		FrontchannelLogoutURI: m.FrontchannelLogoutURI,
+		IDTokenExpiration:     defaultTknExpiration,
+		TknEndpAuthMethod:     m.TknEndpAuthMethod,
+		JWKsURI:               m.JWKsURI,
+		RequireSignedReqObj:   m.RequireSignedReqObj,
+		RequirePAR:            m.RequirePAR,
+		IDTknSignedRespAlg:    m.IDTknSignedRespAlg,
+		UserinfoSignedRespAlg: m.UserinfoSignedRespAlg,
+		RequireDPoP:           m.DPoPBoundAccessTkns,
+	}
+	if m.TknEndpAuthMethod == oidc.AuthMethodNone {
+		p.ClientType = oidc.ClientTypePublic
+	}
+	if len(m.JWKs) != 0 && string(m.JWKs) != "null" {
+		p.JWKs = string(m.JWKs)
+	}
+
+	// user scopes are always available to apps
+	var scopes []string
+	for _, s := range strings.Fields(m.Scope) {
+		if !isUserScope(s) {
+			scopes = append(scopes, s)
+		}
+	}
+	p.AllowedScopes = strings.Join(scopes, " ")
+
+	return p
+}
+
+func isUserScope(s string) bool {
+	for _, scope := range oidc.UserScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func newClientMetadata(client sqlc.Client) clientMetadata {
+	m := clientMetadata{
+		ClientName:            client.Name,
+		RedirectURIs:          strings.Split(client.AuthCallbackUrls, ","),
+		LogoURI:               client.PictureUrl.String,
+		BackchannelLogoutURI:  client.BackchannelLogoutUrl.String,
+		FrontchannelLogoutURI: client.FrontchannelLogoutUri.String,
+		TknEndpAuthMethod:     client.TokenEndpointAuthMethod,
+		Scope:                 client.AllowedScopes.String,
+		JWKsURI:               client.JwksUri.String,
+		RequireSignedReqObj:   client.RequireSignedRequestObject,
+		RequirePAR:            client.RequirePushedAuthorizationRequests,
+		IDTknSignedRespAlg:    client.IDTokenSignedResponseAlg,
+		UserinfoSignedRespAlg: client.UserinfoSignedResponseAlg.String,
+		DPoPBoundAccessTkns:   client.RequireDpop,
+	}
+	if client.LogoutCallbackUrls != "" {
+		m.PostLogoutRedirectURIs = strings.Split(client.LogoutCallbackUrls, ",")
+	}
+	if client.Jwks.Valid {
+		m.JWKs = json.RawMessage(client.Jwks.String)
+	}
+	return m
+}
+
+// metadataErr converts app validation errors into a registration error.
+func metadataErr(errMap map[string]error) registrationErr {
+	fields := make([]string, 0, len(errMap))
+	for f := range errMap {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	f := fields[0]
+	name, ok := metadataFields[f]
+	if !ok {
+		name = f
+	}
+	code := "invalid_client_metadata"
+	if f == "auth_callback_urls" {
+		code = "invalid_redirect_uri"
+	}
+	return registrationErr{
+		Err:     code,
+		ErrDesc: name + ": " + errMap[f].Error(),
+	}
+}
+
+func hashRegistrationTkn(tkn string) string {
+	sum := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(sum[:])
+}
+
+func bearerTkn(c echo.Context) string {
+	tkn, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return tkn
+}
+
+func invalidRegistrationTkn(c echo.Context) error {
+	c.Response().Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	return c.JSON(http.StatusUnauthorized, registrationErr{
+		Err:     "invalid_token",
+		ErrDesc: errInvalidRegistrationTkn.Error(),
+	})
+}
+
+// registerClient creates a new client from the metadata in the request
+// body (RFC 7591). The request must carry the configured initial access
+// token.
+func (a API) registerClient(c echo.Context) error {
+	tkn := bearerTkn(c)
+	if tkn == "" || subtle.ConstantTimeCompare(
+		[]byte(tkn), []byte(a.registration.InitialAccessToken)) != 1 {
+		return invalidRegistrationTkn(c)
+	}
+
+	m := new(clientMetadata)
+	if err := c.Bind(m); err != nil {
+		return c.JSON(http.StatusBadRequest, registrationErr{
+			Err:     "invalid_client_metadata",
+			ErrDesc: "failed to parse request body",
+		})
+	}
+
+	v := newAppValidator(m.appParams(), a.keys.Algs())
+	params, errMap := v.Validate()
+	if errMap["name"] == nil {
+		_, err := a.db.GetClientByName(c.Request().Context(), params.Name)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusInternalServerError, registrationErr{
+				Err:     "server_error",
+				ErrDesc: "database operation failed",
+			})
+		}
+		if err == nil {
+			errMap["name"] = errors.New("name already in use")
+		}
+	}
+	if len(errMap) != 0 {
+		return c.JSON(http.StatusBadRequest, metadataErr(errMap))
+	}
+
+	id, err := util.GenerateRandom(25)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "failed to generate client id",
+		})
+	}
+
+	var secret string
+	var secretHash sql.NullString
+	if params.ClientType == oidc.ClientTypeConfidential {
+		secret, secretHash, err = newClientSecret()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, registrationErr{
+				Err:     "server_error",
+				ErrDesc: "failed to generate client secret",
+			})
+		}
+	}
+
+	registrationTkn, err := util.GenerateRandom(50)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "failed to generate registration access token",
+		})
+	}
+
+	arg := createClientParams(id, secretHash, *params)
+	arg.RegistrationAccessTokenHash = nullString(hashRegistrationTkn(registrationTkn))
+	_, err = a.db.CreateClient(c.Request().Context(), arg)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	client, err := a.db.GetClient(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	resp := a.newRegistrationResp(client)
+	resp.ClientSecret = secret
+	resp.RegistrationAccessTkn = registrationTkn
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// registeredClient returns the client named in the path after checking
+// the registration access token (RFC 7592 section 3).
+func (a API) registeredClient(c echo.Context) (*sqlc.Client, error) {
+	tkn := bearerTkn(c)
+	if tkn == "" {
+		return nil, errInvalidRegistrationTkn
+	}
+	client, err := a.db.GetClient(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return nil, errInvalidRegistrationTkn
+	}
+	if !client.RegistrationAccessTokenHash.Valid {
+		return nil, errInvalidRegistrationTkn
+	}
+	if subtle.ConstantTimeCompare(
+		[]byte(hashRegistrationTkn(tkn)),
+		[]byte(client.RegistrationAccessTokenHash.String)) != 1 {
+		return nil, errInvalidRegistrationTkn
+	}
+	return &client, nil
+}
+
+func (a API) readRegisteredClient(c echo.Context) error {
+	client, err := a.registeredClient(c)
+	if err != nil {
+		return invalidRegistrationTkn(c)
+	}
+	return c.JSON(http.StatusOK, a.newRegistrationResp(*client))
+}
+
+// updateRegisteredClient replaces the metadata of a registered client
+// with the one in the request body (RFC 7592 section 2.2).
+func (a API) updateRegisteredClient(c echo.Context) error {
+	client, err := a.registeredClient(c)
+	if err != nil {
+		return invalidRegistrationTkn(c)
+	}
+
+	req := new(registrationReq)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, registrationErr{
+			Err:     "invalid_client_metadata",
+			ErrDesc: "failed to parse request body",
+		})
+	}
+	if req.ClientID != "" && req.ClientID != client.ID {
+		return c.JSON(http.StatusBadRequest, registrationErr{
+			Err:     "invalid_client_metadata",
+			ErrDesc: "client_id: does not match the registered client",
+		})
+	}
+
+	p := req.appParams()
+	// the access token format has no registration metadata and is only
+	// managed from the console
+	p.AccessTknFormat = client.AccessTokenFormat
+	if p.ClientType != client.ClientType {
+		return c.JSON(http.StatusBadRequest, registrationErr{
+			Err:     "invalid_client_metadata",
+			ErrDesc: "token_endpoint_auth_method: client type can not be changed",
+		})
+	}
+	p.ID = client.ID
+	p.IDTokenExpiration = time.Duration(client.TokenExpiration)
+
+	v := newAppValidator(p, a.keys.Algs())
+	params, errMap := v.Validate()
+	if errMap["name"] == nil {
+		_, err = a.db.GetClientByNameForUnmatchingID(
+			c.Request().Context(),
+			sqlc.GetClientByNameForUnmatchingIDParams{
+				Name: params.Name,
+				ID:   params.ID,
+			},
+		)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusInternalServerError, registrationErr{
+				Err:     "server_error",
+				ErrDesc: "database operation failed",
+			})
+		}
+		if err == nil {
+			errMap["name"] = errors.New("name already in use")
+		}
+	}
+	if len(errMap) != 0 {
+		return c.JSON(http.StatusBadRequest, metadataErr(errMap))
+	}
+
+	err = a.db.UpdateClient(c.Request().Context(), updateClientParams(*params))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	updated, err := a.db.GetClient(c.Request().Context(), client.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	return c.JSON(http.StatusOK, a.newRegistrationResp(updated))
+}
+
+func (a API) deleteRegisteredClient(c echo.Context) error {
+	client, err := a.registeredClient(c)
+	if err != nil {
+		return invalidRegistrationTkn(c)
+	}
+	err = a.db.DeleteClient(c.Request().Context(), client.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, registrationErr{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (a API) newRegistrationResp(client sqlc.Client) registrationResp {
+	return registrationResp{
+		clientMetadata:        newClientMetadata(client),
+		ClientID:              client.ID,
+		ClientIDIssuedAt:      client.CreatedAt.Unix(),
+		RegistrationClientURI: a.baseURL + "/oauth/register/" + client.ID,
+	}
+}
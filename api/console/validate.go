@@ -1,19 +1,31 @@
 package console
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/murtaza-u/ellipsis/api/oidc"
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/conf"
 	"github.com/murtaza-u/ellipsis/view/partial/console"
 )
 
+// scope-token as per RFC 6749 section 3.3
+var scopeRegexp = regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]+$`)
+
 type AppValidator struct {
 	console.AppParams
+	// algorithms the key store holds an active signing key for
+	algs []string
 }
 
-func newAppValidator(p console.AppParams) AppValidator {
-	return AppValidator{AppParams: p}
+func newAppValidator(p console.AppParams, algs []string) AppValidator {
+	return AppValidator{AppParams: p, algs: algs}
 }
 
 func (v AppValidator) Validate() (*console.AppParams, map[string]error) {
@@ -21,12 +33,18 @@ func (v AppValidator) Validate() (*console.AppParams, map[string]error) {
 	if err := v.validateName(); err != nil {
 		errMap["name"] = err
 	}
+	if err := v.validateClientType(); err != nil {
+		errMap["client_type"] = err
+	}
 	if err := v.validateLogoURL(); err != nil {
 		errMap["logo_url"] = err
 	}
 	if err := v.validateBackchannelLogoutURL(); err != nil {
 		errMap["backchannel_logout_url"] = err
 	}
+	if err := v.validateFrontchannelLogoutURI(); err != nil {
+		errMap["frontchannel_logout_uri"] = err
+	}
 	if err := v.validateAuthCallbackURLs(); err != nil {
 		errMap["auth_callback_urls"] = err
 	}
@@ -36,6 +54,27 @@ func (v AppValidator) Validate() (*console.AppParams, map[string]error) {
 	if err := v.validateIDTokenExpiration(); err != nil {
 		errMap["id_token_expiration"] = err
 	}
+	if err := v.validateAllowedScopes(); err != nil {
+		errMap["allowed_scopes"] = err
+	}
+	if err := v.validateTknEndpAuthMethod(); err != nil {
+		errMap["token_endpoint_auth_method"] = err
+	}
+	if err := v.validateJWKsURI(); err != nil {
+		errMap["jwks_uri"] = err
+	}
+	if err := v.validateJWKs(); err != nil {
+		errMap["jwks"] = err
+	}
+	if err := v.validateIDTknSignedRespAlg(); err != nil {
+		errMap["id_token_signed_response_alg"] = err
+	}
+	if err := v.validateUserinfoSignedRespAlg(); err != nil {
+		errMap["userinfo_signed_response_alg"] = err
+	}
+	if err := v.validateAccessTknFormat(); err != nil {
+		errMap["access_token_format"] = err
+	}
 	return &v.AppParams, errMap
 }
 
@@ -47,6 +86,17 @@ func (v *AppValidator) validateName() error {
 	return nil
 }
 
+func (v *AppValidator) validateClientType() error {
+	if v.ClientType == "" {
+		v.ClientType = oidc.ClientTypeConfidential
+	}
+	if v.ClientType != oidc.ClientTypeConfidential &&
+		v.ClientType != oidc.ClientTypePublic {
+		return errors.New("client type must be either confidential or public")
+	}
+	return nil
+}
+
 func validateURL(s string) error {
 	if len(s) > 100 {
 		return errors.New("url too long")
@@ -76,6 +126,14 @@ func (v *AppValidator) validateBackchannelLogoutURL() error {
 	return validateURL(v.BackchannelLogoutURL)
 }
 
+func (v *AppValidator) validateFrontchannelLogoutURI() error {
+	if v.FrontchannelLogoutURI == "" {
+		return nil
+	}
+	v.FrontchannelLogoutURI = strings.TrimSpace(v.FrontchannelLogoutURI)
+	return validateURL(v.FrontchannelLogoutURI)
+}
+
 func validateAndTransformCallbackURLs(urls string) (string, error) {
 	if len(urls) > 1000 {
 		return "", errors.New("value too long")
@@ -130,3 +188,206 @@ func (v AppValidator) validateIDTokenExpiration() error {
 	}
 	return nil
 }
+
+func (v *AppValidator) validateAllowedScopes() error {
+	scopes := strings.Fields(v.AllowedScopes)
+	v.AllowedScopes = strings.Join(scopes, " ")
+	if v.AllowedScopes == "" {
+		return nil
+	}
+	if v.ClientType == oidc.ClientTypePublic {
+		return errors.New("public apps can not use the client credentials grant")
+	}
+	if len(v.AllowedScopes) > 255 {
+		return errors.New("value too long")
+	}
+	for _, s := range scopes {
+		if !scopeRegexp.MatchString(s) {
+			return fmt.Errorf("invalid scope %q", s)
+		}
+		for _, reserved := range oidc.UserScopes {
+			if s == reserved {
+				return fmt.Errorf("scope %q is reserved for users", s)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *AppValidator) validateTknEndpAuthMethod() error {
+	// public apps have no credentials to authenticate with
+	if v.ClientType == oidc.ClientTypePublic {
+		v.TknEndpAuthMethod = oidc.AuthMethodNone
+		return nil
+	}
+	switch v.TknEndpAuthMethod {
+	case "":
+		v.TknEndpAuthMethod = oidc.AuthMethodSecretBasic
+	case oidc.AuthMethodSecretBasic,
+		oidc.AuthMethodSecretPost,
+		oidc.AuthMethodPrivateKeyJWT:
+	default:
+		return errors.New("unsupported token endpoint authentication method")
+	}
+	return nil
+}
+
+// usesKeys reports whether the app needs a registered key set.
+func (v AppValidator) usesKeys() bool {
+	return v.TknEndpAuthMethod == oidc.AuthMethodPrivateKeyJWT ||
+		v.RequireSignedReqObj
+}
+
+func (v *AppValidator) validateJWKsURI() error {
+	v.JWKsURI = strings.TrimSpace(v.JWKsURI)
+	if !v.usesKeys() {
+		v.JWKsURI = ""
+	}
+	if v.JWKsURI == "" {
+		return nil
+	}
+	if err := validateURL(v.JWKsURI); err != nil {
+		return err
+	}
+	// key sets are fetched by the server, and anyone may register an
+	// app once dynamic registration is enabled
+	return util.ValidatePublicURL(context.Background(), v.JWKsURI)
+}
+
+func (v *AppValidator) validateJWKs() error {
+	v.JWKs = strings.TrimSpace(v.JWKs)
+	if !v.usesKeys() {
+		v.JWKs = ""
+		return nil
+	}
+	if v.JWKs == "" && v.JWKsURI == "" {
+		return errors.New("a key set or key set URL is required")
+	}
+	if v.JWKs != "" && v.JWKsURI != "" {
+		return errors.New("provide either a key set or key set URL, not both")
+	}
+	if v.JWKsURI != "" {
+		return nil
+	}
+	if len(v.JWKs) > 10000 {
+		return errors.New("key set too large")
+	}
+	_, err := oidc.ParseJWKs([]byte(v.JWKs))
+	return err
+}
+
+func (v *AppValidator) validateIDTknSignedRespAlg() error {
+	switch v.IDTknSignedRespAlg {
+	case "":
+		v.IDTknSignedRespAlg = oidc.DefaultSigningAlg
+	case conf.AlgEdDSA, conf.AlgRS256, conf.AlgES256:
+	default:
+		return errors.New("unsupported signing algorithm")
+	}
+	return v.validateActiveAlg(v.IDTknSignedRespAlg)
+}
+
+// validateUserinfoSignedRespAlg allows an empty algorithm, in which case
+// userinfo responses are returned as plain JSON.
+func (v *AppValidator) validateUserinfoSignedRespAlg() error {
+	switch v.UserinfoSignedRespAlg {
+	case "":
+		return nil
+	case conf.AlgEdDSA, conf.AlgRS256, conf.AlgES256:
+		return v.validateActiveAlg(v.UserinfoSignedRespAlg)
+	default:
+		return errors.New("unsupported signing algorithm")
+	}
+}
+
+// validateActiveAlg rejects algorithms tokens could not be signed with.
+func (v AppValidator) validateActiveAlg(alg string) error {
+	if !slices.Contains(v.algs, alg) {
+		return fmt.Errorf("no active signing key for %s", alg)
+	}
+	return nil
+}
+
+func (v *AppValidator) validateAccessTknFormat() error {
+	switch v.AccessTknFormat {
+	case "":
+		v.AccessTknFormat = oidc.AccessTknFormatJWT
+	case oidc.AccessTknFormatJWT, oidc.AccessTknFormatOpaque:
+	default:
+		return errors.New("unsupported access token format")
+	}
+	return nil
+}
+
+type APIValidator struct {
+	console.APIParams
+}
+
+func newAPIValidator(p console.APIParams) APIValidator {
+	return APIValidator{APIParams: p}
+}
+
+func (v APIValidator) Validate() (*console.APIParams, map[string]error) {
+	errMap := make(map[string]error)
+	if err := v.validateName(); err != nil {
+		errMap["name"] = err
+	}
+	if err := v.validateIdentifier(); err != nil {
+		errMap["identifier"] = err
+	}
+	if err := v.validateScopes(); err != nil {
+		errMap["scopes"] = err
+	}
+	if err := v.validateTokenExpiration(); err != nil {
+		errMap["token_expiration"] = err
+	}
+	return &v.APIParams, errMap
+}
+
+func (v *APIValidator) validateName() error {
+	v.Name = strings.TrimSpace(v.Name)
+	if len(v.Name) < 2 || len(v.Name) > 50 {
+		return errors.New("name must be between 2 and 50 characters")
+	}
+	return nil
+}
+
+// validateIdentifier requires an absolute URI without a fragment, as
+// resource indicators are (RFC 8707 section 2).
+func (v *APIValidator) validateIdentifier() error {
+	v.Identifier = strings.TrimSpace(v.Identifier)
+	if err := validateURL(v.Identifier); err != nil {
+		return err
+	}
+	u, _ := url.Parse(v.Identifier)
+	if !u.IsAbs() || u.Fragment != "" {
+		return errors.New("identifier must be an absolute URI without a fragment")
+	}
+	return nil
+}
+
+func (v *APIValidator) validateScopes() error {
+	scopes := strings.Fields(v.Scopes)
+	v.Scopes = strings.Join(scopes, " ")
+	if len(v.Scopes) > 255 {
+		return errors.New("value too long")
+	}
+	for _, s := range scopes {
+		if !scopeRegexp.MatchString(s) {
+			return fmt.Errorf("invalid scope %q", s)
+		}
+		for _, reserved := range oidc.UserScopes {
+			if s == reserved {
+				return fmt.Errorf("scope %q is reserved for users", s)
+			}
+		}
+	}
+	return nil
+}
+
+func (v APIValidator) validateTokenExpiration() error {
+	if v.TokenExpiration < 60 || v.TokenExpiration > 86400 {
+		return errors.New("access token expiration must be between 60s to 86400s")
+	}
+	return nil
+}
@@ -25,7 +25,9 @@ func (s Server) LoginPage(c echo.Context) error {
 		Component: layout.Base(
 			"Login | Ellipsis",
 			view.Login(view.LoginParams{
+				Email:     c.QueryParam("login_hint"),
 				ReturnTo:  c.QueryParam("return_to"),
+				Prompt:    c.QueryParam("prompt"),
 				Providers: s.Providers,
 			}, map[string]error{}),
 		),
@@ -45,6 +47,7 @@ func (s Server) Login(c echo.Context) error {
 		})
 	}
 	params.ReturnTo = c.QueryParam("return_to")
+	params.Prompt = c.QueryParam("prompt")
 	params.Providers = s.Providers
 
 	errMap := make(map[string]error)
@@ -160,6 +163,7 @@ func (s Server) Login(c echo.Context) error {
 			ExpiresAt: expiresAt,
 			Browser:   fingerprint.Browser,
 			Os:        fingerprint.OS,
+			AuthTime:  sql.NullTime{Time: time.Now(), Valid: true},
 		},
 	)
 	if err != nil {
@@ -1,9 +1,15 @@
 package api
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/murtaza-u/ellipsis/api/oidc"
+	"github.com/murtaza-u/ellipsis/api/render"
+	"github.com/murtaza-u/ellipsis/view"
+	"github.com/murtaza-u/ellipsis/view/layout"
+
 	"github.com/labstack/echo/v4"
 )
 
@@ -20,6 +26,22 @@ func (s Server) Logout(c echo.Context) error {
 		return c.Redirect(http.StatusTemporaryRedirect, "/login")
 	}
 
-	s.queries.DeleteSession(c.Request().Context(), cookie.Value)
-	return c.Redirect(http.StatusTemporaryRedirect, "/login")
+	// sign the user out of every app they logged into using this session
+	frames, err := oidc.EndSession(
+		c.Request().Context(), s.queries, s.BaseURL, cookie.Value)
+	if err != nil {
+		slog.Error("failed to end session", "error", err)
+		s.queries.DeleteSession(c.Request().Context(), cookie.Value)
+	}
+
+	if len(frames) == 0 {
+		return c.Redirect(http.StatusTemporaryRedirect, "/login")
+	}
+	return render.Do(render.Params{
+		Ctx: c,
+		Component: layout.Base(
+			"Logout | Ellipsis",
+			view.FrontchannelLogout(frames, "/login"),
+		),
+	})
 }
@@ -3,7 +3,6 @@ package me
 import (
 	"github.com/murtaza-u/ellipsis/api/middleware"
 	"github.com/murtaza-u/ellipsis/fs"
-	"github.com/murtaza-u/ellipsis/internal/conf"
 	"github.com/murtaza-u/ellipsis/internal/sqlc"
 
 	"github.com/labstack/echo/v4"
@@ -11,15 +10,13 @@ import (
 
 type API struct {
 	db      *sqlc.Queries
-	key     conf.Key
 	baseURL string
 	fs      fs.Storage
 }
 
-func New(db *sqlc.Queries, key conf.Key, baseURL string, fs fs.Storage) API {
+func New(db *sqlc.Queries, baseURL string, fs fs.Storage) API {
 	return API{
 		db:      db,
-		key:     key,
 		baseURL: baseURL,
 		fs:      fs,
 	}
@@ -31,6 +28,7 @@ func (a API) Register(app *echo.Echo) {
 	grp := app.Group("", auth.Required, auth.AuthInfo)
 	grp.GET("/", a.Profile)
 	grp.POST("/", a.ChangeAvatar)
+	grp.POST("/profile", a.ChangeProfile)
 	grp.GET("/change-password", a.ChangePasswordPage)
 	grp.POST("/change-password", a.ChangePassword)
 	grp.GET("/session", a.SessionPage, auth.AuthInfo)
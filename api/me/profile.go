@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/murtaza-u/ellipsis/api/apierr"
 	"github.com/murtaza-u/ellipsis/api/middleware"
@@ -23,15 +26,51 @@ import (
 const maxUploadSize = 1024 * 512
 
 func (a API) Profile(c echo.Context) error {
-	var avatarURL string
+	var userID string
 	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
-		avatarURL = ctx.AvatarURL
+		userID = ctx.UserID
+	}
+	u, err := a.db.GetUser(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r := c.Response()
+			r.Header().Set("HX-Redirect", "/logout")
+
+			// render empty template
+			h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
+			return h.Component.Render(c.Request().Context(), r)
+		}
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read user from db: %w", err),
+			layout.Base(
+				"My Account | Ellipsis",
+				view.Error(
+					"database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
 	}
+
 	return render.Do(render.Params{
 		Ctx: c,
 		Component: layout.Base(
 			"My Account | Ellipsis",
-			view.Me("/", avatarURL, me.Profile(avatarURL)),
+			view.Me("/", u.AvatarUrl.String, me.Profile(
+				u.AvatarUrl.String,
+				me.ProfileParams{
+					Name:          u.Name.String,
+					GivenName:     u.GivenName.String,
+					FamilyName:    u.FamilyName.String,
+					PhoneNumber:   u.PhoneNumber.String,
+					StreetAddress: u.StreetAddress.String,
+					Locality:      u.Locality.String,
+					Region:        u.Region.String,
+					PostalCode:    u.PostalCode.String,
+					Country:       u.Country.String,
+				},
+			)),
 		),
 	})
 }
@@ -332,3 +371,108 @@ func (a API) ChangePassword(c echo.Context) error {
 		),
 	})
 }
+
+func (a API) ChangeProfile(c echo.Context) error {
+	form := new(me.ProfileParams)
+	if err := c.Bind(form); err != nil {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"failed to parse form",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	var userID string
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		userID = ctx.UserID
+	}
+	if userID == "" {
+		r := c.Response()
+		r.Header().Set("HX-Redirect", "/logout")
+
+		// render empty template
+		h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
+		return h.Component.Render(c.Request().Context(), r)
+	}
+
+	errMap := validateProfile(form)
+	if len(errMap) != 0 {
+		return render.Do(render.Params{
+			Ctx:       c,
+			Component: me.ChangeProfile(*form, errMap, false),
+			Status:    http.StatusBadRequest,
+		})
+	}
+
+	err := a.db.UpdateUserProfile(
+		c.Request().Context(),
+		sqlc.UpdateUserProfileParams{
+			ID:            userID,
+			Name:          nullString(form.Name),
+			GivenName:     nullString(form.GivenName),
+			FamilyName:    nullString(form.FamilyName),
+			PhoneNumber:   nullString(form.PhoneNumber),
+			StreetAddress: nullString(form.StreetAddress),
+			Locality:      nullString(form.Locality),
+			Region:        nullString(form.Region),
+			PostalCode:    nullString(form.PostalCode),
+			Country:       nullString(form.Country),
+			UpdatedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+		},
+	)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to update user's profile in db: %w", err),
+			view.Error(
+				"database operation failed",
+				http.StatusInternalServerError,
+			),
+		)
+	}
+
+	return render.Do(render.Params{
+		Ctx:       c,
+		Component: me.ChangeProfile(*form, map[string]error{}, true),
+	})
+}
+
+var phoneNumberRegex = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{3,19}$`)
+
+// validateProfile trims the profile fields and checks them against the
+// column sizes.
+func validateProfile(p *me.ProfileParams) map[string]error {
+	errMap := make(map[string]error)
+	for _, f := range []struct {
+		name   string
+		value  *string
+		maxLen int
+	}{
+		{"name", &p.Name, 100},
+		{"given_name", &p.GivenName, 50},
+		{"family_name", &p.FamilyName, 50},
+		{"phone_number", &p.PhoneNumber, 20},
+		{"street_address", &p.StreetAddress, 255},
+		{"locality", &p.Locality, 50},
+		{"region", &p.Region, 50},
+		{"postal_code", &p.PostalCode, 20},
+		{"country", &p.Country, 50},
+	} {
+		*f.value = strings.TrimSpace(*f.value)
+		if len(*f.value) > f.maxLen {
+			errMap[f.name] = fmt.Errorf("must be less than %d characters", f.maxLen)
+		}
+	}
+	if errMap["phone_number"] == nil && p.PhoneNumber != "" &&
+		!phoneNumberRegex.MatchString(p.PhoneNumber) {
+		errMap["phone_number"] = errors.New("invalid phone number")
+	}
+	return errMap
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
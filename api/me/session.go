@@ -1,14 +1,10 @@
 package me
 
 import (
-	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
-	"strings"
-	"time"
 
 	"github.com/murtaza-u/ellipsis/api/apierr"
 	"github.com/murtaza-u/ellipsis/api/middleware"
@@ -19,7 +15,6 @@ import (
 	"github.com/murtaza-u/ellipsis/view/partial/me"
 
 	"github.com/a-h/templ"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
@@ -107,6 +102,20 @@ func (a API) DeleteSessionPage(c echo.Context) error {
 			),
 		)
 	}
+
+	// warn up front that the app will not be notified
+	if sess.ClientID.Valid && !sess.BackchannelLogoutUrl.Valid {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Revoke Session | Ellipsis",
+				me.DeleteSessionUnsupportedBackchannelLogout(
+					sess.ClientName.String,
+					sess.ID,
+				),
+			),
+		})
+	}
 	return render.Do(render.Params{
 		Ctx: c,
 		Component: layout.Base(
@@ -117,8 +126,7 @@ func (a API) DeleteSessionPage(c echo.Context) error {
 }
 
 type deleteSessionParams struct {
-	ID    string `form:"id"`
-	Force bool   `form:"force"`
+	ID string `form:"id"`
 }
 
 func (a API) DeleteSession(c echo.Context) error {
@@ -179,25 +187,15 @@ func (a API) DeleteSession(c echo.Context) error {
 		)
 	}
 
-	if form.Force || !sess.ClientID.Valid {
-		err := a.db.DeleteSession(c.Request().Context(), form.ID)
+	// ending a browser session signs the user out of every app they
+	// logged into using it
+	if !sess.ClientID.Valid {
+		frames, err := oidc.EndSession(
+			c.Request().Context(), a.db, a.baseURL, form.ID)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return render.Do(render.Params{
-					Ctx: c,
-					Component: layout.Base(
-						"Revoke Session | Ellipsis",
-						view.Error(
-							"invalid session id",
-							http.StatusBadRequest,
-						),
-					),
-					Status: http.StatusBadRequest,
-				})
-			}
 			return apierr.New(
 				http.StatusInternalServerError,
-				fmt.Errorf("failed to delete session from db: %w", err),
+				err,
 				layout.Base(
 					"Revoke Session | Ellipsis",
 					view.Error(
@@ -207,6 +205,15 @@ func (a API) DeleteSession(c echo.Context) error {
 				),
 			)
 		}
+		if len(frames) != 0 {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: layout.Base(
+					"Revoke Session | Ellipsis",
+					view.FrontchannelLogout(frames, "/session"),
+				),
+			})
+		}
 
 		isBoosted := c.Request().Header.Get("HX-Boosted") != ""
 		if !isBoosted {
@@ -221,53 +228,8 @@ func (a API) DeleteSession(c echo.Context) error {
 		return h.Component.Render(c.Request().Context(), r)
 	}
 
-	if !sess.BackchannelLogoutUrl.Valid {
-		return render.Do(render.Params{
-			Ctx: c,
-			Component: layout.Base(
-				"Revoke Session | Ellipsis",
-				me.DeleteSessionUnsupportedBackchannelLogout(
-					sess.ClientName.String,
-					sess.ID,
-				),
-			),
-		})
-	}
-
-	tkn, err := a.createLogoutTkn(sess.ID, sess.ClientID.String)
-	if err != nil {
-		return apierr.New(
-			http.StatusInternalServerError,
-			fmt.Errorf("failed to generate logout token: %w", err),
-			layout.Base(
-				"Revoke Session | Ellipsis",
-				view.Error(
-					"failed to generate logout token",
-					http.StatusInternalServerError,
-				),
-			),
-		)
-	}
-
-	err = a.backchannelLogout(
-		c.Request().Context(),
-		sess.BackchannelLogoutUrl.String,
-		tkn,
-	)
-	if err != nil {
-		return render.Do(render.Params{
-			Ctx: c,
-			Component: layout.Base(
-				"Revoke Session | Ellipsis",
-				me.DeleteSessionBackchannelLogoutFailure(
-					sess.ClientName.String,
-					sess.ID,
-				),
-			),
-			Status: http.StatusExpectationFailed,
-		})
-	}
-
+	// the session is revoked right away while the logout token, if the app
+	// supports back-channel logout, is delivered in the background
 	err = a.db.DeleteSession(c.Request().Context(), form.ID)
 	if err != nil {
 		return apierr.New(
@@ -283,6 +245,24 @@ func (a API) DeleteSession(c echo.Context) error {
 		)
 	}
 
+	if sess.BackchannelLogoutUrl.Valid {
+		err = oidc.EnqueueBackchannelLogout(
+			c.Request().Context(), a.db, sess.ClientID.String, sess.UserID, sess.ID)
+		if err != nil {
+			return apierr.New(
+				http.StatusInternalServerError,
+				err,
+				layout.Base(
+					"Revoke Session | Ellipsis",
+					view.Error(
+						"session revoked, but failed to schedule back-channel logout",
+						http.StatusInternalServerError,
+					),
+				),
+			)
+		}
+	}
+
 	isBoosted := c.Request().Header.Get("HX-Boosted") != ""
 	if !isBoosted {
 		return c.Redirect(http.StatusFound, "/session")
@@ -295,50 +275,3 @@ func (a API) DeleteSession(c echo.Context) error {
 	h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
 	return h.Component.Render(c.Request().Context(), r)
 }
-
-func (a API) createLogoutTkn(sid, clientID string) (string, error) {
-	tkn := jwt.NewWithClaims(jwt.SigningMethodEdDSA, oidc.LogoutTknClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    a.baseURL,
-			Subject:   clientID,
-			Audience:  jwt.ClaimStrings{clientID},
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 2)),
-		},
-		Events: map[string]struct{}{
-			"http://schemas.openid.net/event/backchannel-logout": {},
-		},
-		SID: sid,
-	})
-	return tkn.SignedString(a.key.Priv)
-}
-
-func (a API) backchannelLogout(ctx context.Context, uri, tkn string) error {
-	q := make(url.Values)
-	q.Set("logout_token", tkn)
-
-	ctx, cancel := context.WithTimeout(ctx, time.Second*7)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		uri,
-		strings.NewReader(q.Encode()),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create new request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to call backchannel logout URI")
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("client responded with non-200 status code: %s", resp.Status)
-	}
-
-	return nil
-}
@@ -31,6 +31,28 @@ type CtxWithAuthInfo struct {
 	UserID    string
 	AvatarURL string
 	Email     string
+	// AuthTime is when the user last authenticated.
+	AuthTime time.Time
+}
+
+// LoginURL returns the login page URL which sends the user back to
+// returnTo once authenticated. prompt=login forces re-authentication of
+// an already authenticated user and loginHint prefills the email.
+func LoginURL(returnTo, prompt, loginHint string) string {
+	q := make(url.Values)
+	if returnTo != "" {
+		q.Set("return_to", returnTo)
+	}
+	if prompt != "" {
+		q.Set("prompt", prompt)
+	}
+	if loginHint != "" {
+		q.Set("login_hint", loginHint)
+	}
+	if len(q) == 0 {
+		return "/login"
+	}
+	return "/login?" + q.Encode()
 }
 
 func (m AuthMiddleware) AuthInfo(next echo.HandlerFunc) echo.HandlerFunc {
@@ -43,20 +65,28 @@ func (m AuthMiddleware) AuthInfo(next echo.HandlerFunc) echo.HandlerFunc {
 		if err != nil {
 			return next(c)
 		}
+		if time.Until(sess.ExpiresAt) <= 0 {
+			return next(c)
+		}
+		// sessions created before auth_time was tracked
+		authTime := sess.CreatedAt
+		if sess.AuthTime.Valid {
+			authTime = sess.AuthTime.Time
+		}
 		return next(CtxWithAuthInfo{
 			Context:   c,
 			SessionID: sess.ID,
 			UserID:    sess.UserID,
 			Email:     sess.Email,
 			AvatarURL: sess.AvatarUrl.String,
+			AuthTime:  authTime,
 		})
 	}
 }
 
 func (m AuthMiddleware) AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		returnTo := url.QueryEscape(c.Request().URL.RequestURI())
-		redirectTo := "/login?return_to=" + returnTo
+		redirectTo := LoginURL(c.Request().URL.RequestURI(), "", "")
 		cookie, err := c.Cookie("auth_session")
 		if err != nil {
 			return c.Redirect(http.StatusTemporaryRedirect, redirectTo)
@@ -84,8 +114,7 @@ func (m AuthMiddleware) AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
 
 func (m AuthMiddleware) Required(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		returnTo := url.QueryEscape(c.Request().URL.RequestURI())
-		redirectTo := "/login?return_to=" + returnTo
+		redirectTo := LoginURL(c.Request().URL.RequestURI(), "", "")
 		cookie, err := c.Cookie("auth_session")
 		if err != nil {
 			return c.Redirect(http.StatusTemporaryRedirect, redirectTo)
@@ -113,6 +142,10 @@ func (m AuthMiddleware) Required(next echo.HandlerFunc) echo.HandlerFunc {
 
 func (m AuthMiddleware) AlreadyAuthenticated(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		// re-authentication was explicitly requested
+		if c.QueryParam("prompt") == "login" {
+			return next(c)
+		}
 		cookie, err := c.Cookie("auth_session")
 		if err != nil {
 			return next(c)
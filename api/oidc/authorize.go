@@ -57,22 +57,6 @@ func (a API) consent(c echo.Context) error {
 		})
 	}
 
-	if form.Consent != "granted" {
-		err := newAuthorizeErr("denied", "user did not consent")
-		callback := err.AttachTo(form.Callback)
-
-		if !isBoosted {
-			return c.Redirect(http.StatusTemporaryRedirect, callback)
-		}
-
-		r := c.Response()
-		r.Header().Set("HX-Redirect", callback)
-
-		// render empty template
-		h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
-		return h.Component.Render(c.Request().Context(), r)
-	}
-
 	client, err := a.DB.GetClient(c.Request().Context(), form.ClientID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -95,18 +79,90 @@ func (a API) consent(c echo.Context) error {
 		)
 	}
 
+	// the callback is sent back by the browser and must be one of the
+	// app's registered URLs, or errors would be delivered anywhere
+	redirectTo := matchRedirectURI(client, form.Callback)
+	if redirectTo == "" {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: view.Error(
+				"Unauthorized redirect URI",
+				http.StatusBadRequest,
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	if form.Consent != "granted" {
+		err := newAuthorizeErr("access_denied", "user did not consent")
+
+		// state and response mode travel with the original
+		// authorization request
+		var q url.Values
+		if u, err := url.Parse(form.ReturnTo); err == nil {
+			q = u.Query()
+		}
+		if q.Has("request") || q.Has("request_uri") {
+			obj, err := a.resolveRequestObject(
+				c.Request().Context(), client, q)
+			if err == nil {
+				q = obj.Params
+			}
+		}
+		rdr := authzRedirect{
+			URI:   redirectTo,
+			Mode:  q.Get("response_mode"),
+			State: q.Get("state"),
+		}
+		if !isValidResponseMode(rdr.Mode) {
+			rdr.Mode = ResponseModeQuery
+		}
+
+		if !isBoosted || rdr.Mode == ResponseModeFormPost {
+			return rdr.Send(c, err.Values())
+		}
+		callback := rdr.URL(err.Values())
+
+		r := c.Response()
+		r.Header().Set("HX-Redirect", callback)
+
+		// render empty template
+		h := templ.Handler(view.Empty(), templ.WithStatus(http.StatusOK))
+		return h.Component.Render(c.Request().Context(), r)
+	}
+
 	var userID string
 	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
 		userID = ctx.UserID
 	}
 
-	_, err = a.DB.CreateAuthzHistory(
+	// consent may be asked again (prompt=consent) for an app the user
+	// already authorized. The time of consent is renewed so that the
+	// replayed authorization request does not ask again.
+	_, err = a.DB.GetAuthzHistory(
 		c.Request().Context(),
-		sqlc.CreateAuthzHistoryParams{
+		sqlc.GetAuthzHistoryParams{
 			UserID:   userID,
 			ClientID: client.ID,
 		},
 	)
+	if err == nil {
+		err = a.DB.UpdateAuthzHistory(
+			c.Request().Context(),
+			sqlc.UpdateAuthzHistoryParams{
+				UserID:   userID,
+				ClientID: client.ID,
+			},
+		)
+	} else if errors.Is(err, sql.ErrNoRows) {
+		_, err = a.DB.CreateAuthzHistory(
+			c.Request().Context(),
+			sqlc.CreateAuthzHistoryParams{
+				UserID:   userID,
+				ClientID: client.ID,
+			},
+		)
+	}
 	if err != nil {
 		return apierr.New(
 			http.StatusInternalServerError,
@@ -181,24 +237,45 @@ func (a API) authorize(c echo.Context) error {
 		)
 	}
 
-	p.RedirectURI = strings.TrimSpace(p.RedirectURI)
-	p.RedirectURI = strings.TrimSuffix(p.RedirectURI, "/")
-
-	var redirectTo string
-	for _, u := range strings.Split(client.AuthCallbackUrls, ",") {
-		if p.RedirectURI == "" || u == p.RedirectURI {
-			redirectTo = u
-			break
+	if p.Request != "" || p.RequestURI != "" {
+		obj, err := a.resolveRequestObject(
+			c.Request().Context(), client, c.QueryParams())
+		if err != nil {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: layout.Base(
+					"Authorization | Ellipsis",
+					view.Error(err.Error(), http.StatusBadRequest),
+				),
+				Status: http.StatusBadRequest,
+			})
 		}
+		p = authorizeParamsFrom(obj.Params)
+		p.issuedAt = obj.IssuedAt
+		p.jti = obj.JTI
+		p.expiresAt = obj.ExpiresAt
+		p.pushedID = obj.PushedID
+	} else if client.RequireSignedRequestObject {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Authorization | Ellipsis",
+				view.Error(
+					"App requires a signed request object",
+					http.StatusBadRequest,
+				),
+			),
+			Status: http.StatusBadRequest,
+		})
 	}
 
-	if redirectTo == "" {
+	if client.RequirePushedAuthorizationRequests && p.pushedID == "" {
 		return render.Do(render.Params{
 			Ctx: c,
 			Component: layout.Base(
 				"Authorization | Ellipsis",
 				view.Error(
-					"Unauthorized redirect URI",
+					"App requires pushed authorization requests",
 					http.StatusBadRequest,
 				),
 			),
@@ -206,23 +283,89 @@ func (a API) authorize(c echo.Context) error {
 		})
 	}
 
-	var userID string
-	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
-		userID = ctx.UserID
-	}
-	if userID == "" {
+	redirectTo := matchRedirectURI(client, p.RedirectURI)
+	if redirectTo == "" {
 		return render.Do(render.Params{
 			Ctx: c,
 			Component: layout.Base(
 				"Authorization | Ellipsis",
 				view.Error(
-					"An internal error occured",
-					http.StatusInternalServerError,
+					"Unauthorized redirect URI",
+					http.StatusBadRequest,
 				),
 			),
-			Status: http.StatusInternalServerError,
+			Status: http.StatusBadRequest,
 		})
 	}
+
+	redirectStat := http.StatusTemporaryRedirect
+	rdr := authzRedirect{
+		URI:   redirectTo,
+		Mode:  p.ResponseMode,
+		State: p.State,
+	}
+
+	if !isValidResponseMode(p.ResponseMode) {
+		rdr.Mode = ResponseModeQuery
+		err := newAuthorizeErr("invalid_request", "unsupported response mode")
+		return rdr.Send(c, err.Values())
+	}
+
+	prompt, err := parsePrompt(p.Prompt)
+	if err != nil {
+		err := newAuthorizeErr("invalid_request", err.Error())
+		return rdr.Send(c, err.Values())
+	}
+	maxAge, err := parseMaxAge(p.MaxAge)
+	if err != nil {
+		err := newAuthorizeErr("invalid_request", err.Error())
+		return rdr.Send(c, err.Values())
+	}
+	resource := resourceParam(p.Resource, p.Audience)
+	api, err := a.resolveResource(c.Request().Context(), resource)
+	if err != nil {
+		if !errors.Is(err, errInvalidTarget) {
+			err := newAuthorizeErr("internal_server_error",
+				"database operation failed")
+			return rdr.Send(c, err.Values())
+		}
+		err := newAuthorizeErr("invalid_target", err.Error())
+		return rdr.Send(c, err.Values())
+	}
+	returnTo := withoutPrompt(c.Request().URL)
+
+	var userID, sessID string
+	var authTime time.Time
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		userID = ctx.UserID
+		sessID = ctx.SessionID
+		authTime = ctx.AuthTime
+	}
+
+	// request objects are replayed as is once the user logs in, hence
+	// prompt=login only applies to sessions older than the request
+	forceLogin := prompt[PromptLogin]
+	if !p.issuedAt.IsZero() && !authTime.Before(p.issuedAt) {
+		forceLogin = false
+	}
+
+	isStale := maxAge >= 0 && time.Since(authTime) > maxAge
+	if userID == "" || forceLogin || isStale {
+		if prompt[PromptNone] {
+			err := newAuthorizeErr("login_required",
+				"user must authenticate")
+			return rdr.Send(c, err.Values())
+		}
+		// already authenticated users must be allowed back on the
+		// login page
+		var loginPrompt string
+		if userID != "" {
+			loginPrompt = PromptLogin
+		}
+		return c.Redirect(redirectStat, middleware.LoginURL(
+			returnTo, loginPrompt, p.LoginHint))
+	}
+
 	u, err := a.DB.GetUser(c.Request().Context(), userID)
 	if err != nil {
 		return apierr.New(
@@ -238,23 +381,14 @@ func (a API) authorize(c echo.Context) error {
 		)
 	}
 
-	_, err = a.DB.GetAuthzHistory(
+	hist, err := a.DB.GetAuthzHistory(
 		c.Request().Context(),
 		sqlc.GetAuthzHistoryParams{
 			UserID:   u.ID,
 			ClientID: client.ID,
 		},
 	)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return render.Do(render.Params{
-				Ctx: c,
-				Component: layout.Base(
-					"Authorize | Ellipsis",
-					view.Authorize(redirectTo, c.Request().RequestURI, u, client),
-				),
-			})
-		}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return apierr.New(
 			http.StatusInternalServerError,
 			fmt.Errorf("failed to read authz history from db: %w", err),
@@ -267,60 +401,103 @@ func (a API) authorize(c echo.Context) error {
 			),
 		)
 	}
-
-	redirectStat := http.StatusTemporaryRedirect
+	// same as prompt=login, prompt=consent only applies to consents
+	// given before the request object was issued
+	forceConsent := prompt[PromptConsent]
+	if err == nil && !p.issuedAt.IsZero() && !hist.AuthorizedAt.Before(p.issuedAt) {
+		forceConsent = false
+	}
+	if err != nil || forceConsent {
+		if prompt[PromptNone] {
+			err := newAuthorizeErr("consent_required",
+				"user must consent")
+			return rdr.Send(c, err.Values())
+		}
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Authorize | Ellipsis",
+				view.Authorize(redirectTo, returnTo, u, client, api, strings.Fields(p.Scope)),
+			),
+		})
+	}
 
 	if p.ResponseType != "code" {
 		err := newAuthorizeErr("bad_request", "response type not supported")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+		return rdr.Send(c, err.Values())
 	}
 
-	if p.IDTknSignedRespAlg != "" && p.IDTknSignedRespAlg != "EdDSA" {
+	if p.IDTknSignedRespAlg != "" &&
+		p.IDTknSignedRespAlg != client.IDTokenSignedResponseAlg {
 		err := newAuthorizeErr("bad_request",
-			"unsupported id token signing algorithm")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+			"id token signing algorithm does not match the app's")
+		return rdr.Send(c, err.Values())
 	}
 
-	scopes := strings.Split(p.Scope, " ")
-	if len(scopes) == 0 {
-		err := newAuthorizeErr("bad_request", "missing scope")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+	if err := validateUserScopes(p.Scope, apiScopes(api)); err != nil {
+		err := newAuthorizeErr("bad_request", err.Error())
+		return rdr.Send(c, err.Values())
 	}
-	var hasOpenIDScope, hasProfileScope, hasInvalidScope bool
-	for _, s := range scopes {
-		if s == ScopeOIDC {
-			hasOpenIDScope = true
-			continue
-		}
-		if s == ScopeProfile {
-			hasProfileScope = true
-			continue
-		}
-		hasInvalidScope = true
-		break
+
+	// code challenge method defaults to "plain" (RFC 7636 section 4.3)
+	if p.CodeChallenge != "" && p.CodeChallengeMethod == "" {
+		p.CodeChallengeMethod = CodeChallengeMethodPlain
 	}
-	if hasInvalidScope {
-		err := newAuthorizeErr("bad_request", "unsupported scope")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+	if err := validateCodeChallenge(p.CodeChallenge, p.CodeChallengeMethod); err != nil {
+		err := newAuthorizeErr("invalid_request", err.Error())
+		return rdr.Send(c, err.Values())
 	}
-	if !hasOpenIDScope {
-		err := newAuthorizeErr("bad_request", "missing openid scope")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+	if client.ClientType == ClientTypePublic && p.CodeChallenge == "" {
+		err := newAuthorizeErr("invalid_request",
+			"code challenge required for public clients")
+		return rdr.Send(c, err.Values())
 	}
-	if !hasProfileScope {
-		err := newAuthorizeErr("bad_request", "missing profile scope")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+
+	if len(p.Nonce) > 255 {
+		err := newAuthorizeErr("invalid_request", "nonce too long")
+		return rdr.Send(c, err.Values())
 	}
 
 	code, err := util.GenerateRandom(13)
 	if err != nil {
 		err := newAuthorizeErr("internal_server_error",
 			"failed to generate authorization code")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+		return rdr.Send(c, err.Values())
 	}
 
 	fingerprint := util.ParseUA(c.Request().Header.Get("User-Agent"))
 
+	// pushed request uris are single use. Only the request that removed
+	// it gets a code.
+	if p.pushedID != "" {
+		res, err := a.DB.DeletePushedAuthzRequest(c.Request().Context(), p.pushedID)
+		if err != nil {
+			err := newAuthorizeErr("internal_server_error",
+				"database operation failed")
+			return rdr.Send(c, err.Values())
+		}
+		if n, err := res.RowsAffected(); err != nil || n != 1 {
+			err := newAuthorizeErr("invalid_request_uri",
+				"request uri has already been used")
+			return rdr.Send(c, err.Values())
+		}
+	}
+
+	// request objects are single use
+	if p.jti != "" {
+		err := a.useJTI(c.Request().Context(), client.ID, p.jti, p.expiresAt)
+		if err != nil {
+			if errors.Is(err, errReplayedJTI) {
+				err := newAuthorizeErr("invalid_request_object",
+					"request object has already been used")
+				return rdr.Send(c, err.Values())
+			}
+			err := newAuthorizeErr("internal_server_error",
+				"database operation failed")
+			return rdr.Send(c, err.Values())
+		}
+	}
+
 	_, err = a.DB.CreateAuthzCode(
 		c.Request().Context(),
 		sqlc.CreateAuthzCodeParams{
@@ -328,35 +505,130 @@ func (a API) authorize(c echo.Context) error {
 			UserID:   userID,
 			ClientID: client.ID,
 			Scopes:   p.Scope,
-			Browser:  fingerprint.Browser,
-			Os:       fingerprint.OS,
+			CodeChallenge: sql.NullString{
+				String: p.CodeChallenge,
+				Valid:  p.CodeChallenge != "",
+			},
+			CodeChallengeMethod: sql.NullString{
+				String: p.CodeChallengeMethod,
+				Valid:  p.CodeChallenge != "",
+			},
+			Nonce: sql.NullString{
+				String: p.Nonce,
+				Valid:  p.Nonce != "",
+			},
+			AuthTime: sql.NullTime{
+				Time:  authTime,
+				Valid: !authTime.IsZero(),
+			},
+			Browser: fingerprint.Browser,
+			Os:      fingerprint.OS,
 			ExpiresAt: sql.NullTime{
 				Time:  time.Now().Add(time.Minute * 5),
 				Valid: true,
 			},
+			// client sessions are linked to the browser session they
+			// were spawned from for single logout
+			SessionID: sql.NullString{String: sessID, Valid: sessID != ""},
+			Resource:  sql.NullString{String: resource, Valid: resource != ""},
 		},
 	)
 	if err != nil {
 		err := newAuthorizeErr("internal_server_error",
 			"database operation failed")
-		return c.Redirect(redirectStat, err.AttachTo(redirectTo))
+		return rdr.Send(c, err.Values())
 	}
 
-	return c.Redirect(http.StatusFound, fmt.Sprintf(
-		"%s?code=%s&state=%s",
-		redirectTo,
-		url.QueryEscape(code),
-		url.QueryEscape(p.State),
-	))
+	return rdr.Send(c, url.Values{"code": {code}})
 }
 
 type authorizeParams struct {
-	ClientID           string `query:"client_id"`
-	ResponseType       string `query:"response_type"`
-	Scope              string `query:"scope"`
-	State              string `query:"state"`
-	RedirectURI        string `query:"redirect_uri"`
-	IDTknSignedRespAlg string `query:"id_token_signed_response_alg"`
+	ClientID            string `query:"client_id"`
+	ResponseType        string `query:"response_type"`
+	Scope               string `query:"scope"`
+	State               string `query:"state"`
+	RedirectURI         string `query:"redirect_uri"`
+	IDTknSignedRespAlg  string `query:"id_token_signed_response_alg"`
+	CodeChallenge       string `query:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method"`
+	Nonce               string `query:"nonce"`
+	Prompt              string `query:"prompt"`
+	MaxAge              string `query:"max_age"`
+	LoginHint           string `query:"login_hint"`
+	ResponseMode        string `query:"response_mode"`
+	Request             string `query:"request"`
+	RequestURI          string `query:"request_uri"`
+	Resource            string `query:"resource"`
+	Audience            string `query:"audience"`
+
+	// time at which the request object, if any, was issued
+	issuedAt time.Time
+	// jti and expiry of the request object, if any
+	jti       string
+	expiresAt time.Time
+	// pushed authorization request the parameters were read from
+	pushedID string
+}
+
+// matchRedirectURI returns the registered callback URL matching uri. The
+// first registered URL is used when uri is empty.
+func matchRedirectURI(client sqlc.Client, uri string) string {
+	uri = strings.TrimSpace(uri)
+	uri = strings.TrimSuffix(uri, "/")
+	for _, u := range strings.Split(client.AuthCallbackUrls, ",") {
+		if uri == "" || u == uri {
+			return u
+		}
+	}
+	return ""
+}
+
+func authorizeParamsFrom(v url.Values) *authorizeParams {
+	return &authorizeParams{
+		ClientID:            v.Get("client_id"),
+		ResponseType:        v.Get("response_type"),
+		Scope:               v.Get("scope"),
+		State:               v.Get("state"),
+		RedirectURI:         v.Get("redirect_uri"),
+		IDTknSignedRespAlg:  v.Get("id_token_signed_response_alg"),
+		CodeChallenge:       v.Get("code_challenge"),
+		CodeChallengeMethod: v.Get("code_challenge_method"),
+		Nonce:               v.Get("nonce"),
+		Prompt:              v.Get("prompt"),
+		MaxAge:              v.Get("max_age"),
+		LoginHint:           v.Get("login_hint"),
+		ResponseMode:        v.Get("response_mode"),
+		Resource:            v.Get("resource"),
+		Audience:            v.Get("audience"),
+	}
+}
+
+// validateUserScopes checks the scopes requested on behalf of an end-user.
+// The openid scope is mandatory. Scopes defined by the requested API, if
+// any, are allowed as well.
+func validateUserScopes(scope string, apiScopes []string) error {
+	// the scope is stored along with the grant, in a column of 255
+	// characters
+	if len(scope) > 255 {
+		return errors.New("scope too long")
+	}
+	scopes := strings.Split(scope, " ")
+	var hasOpenIDScope bool
+	for _, s := range scopes {
+		switch {
+		case s == ScopeOIDC:
+			hasOpenIDScope = true
+		case s == ScopeProfile, s == ScopeEmail, s == ScopePhone, s == ScopeAddress:
+		case s == ScopeOfflineAccess:
+		case hasScope(apiScopes, s):
+		default:
+			return errors.New("unsupported scope")
+		}
+	}
+	if !hasOpenIDScope {
+		return errors.New("missing openid scope")
+	}
+	return nil
 }
 
 type authorizeErr struct {
@@ -383,8 +655,15 @@ func (a authorizeErr) Desc() string {
 	return url.QueryEscape(a.desc)
 }
 
+// Values returns the error encoded as authorization response parameters.
+func (a authorizeErr) Values() url.Values {
+	return url.Values{
+		"error":             {a.name},
+		"error_description": {a.desc},
+	}
+}
+
+// AttachTo merges the error into the query component of baseURL.
 func (a authorizeErr) AttachTo(baseURL string) string {
-	return fmt.Sprintf(
-		"%s?error=%s&error_description=%s",
-		baseURL, a.Name(), a.Desc())
+	return withQuery(baseURL, a.Values())
 }
@@ -1,16 +1,46 @@
 package oidc
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"strings"
+
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
 
 type AccessTknClaims struct {
 	jwt.RegisteredClaims
-	UserID string   `json:"user_id"`
-	Scopes []string `json:"scopes"`
+	UserID   string   `json:"user_id,omitempty"`
+	ClientID string   `json:"client_id"`
+	SID      string   `json:"sid,omitempty"`
+	Scopes   []string `json:"scopes"`
+	// Act is only set on tokens issued through token exchange.
+	Act *ActClaim `json:"act,omitempty"`
+	// Cnf is only set on tokens bound to a DPoP key.
+	Cnf *CnfClaim `json:"cnf,omitempty"`
+}
+
+// ActClaim identifies the party acting on behalf of the subject of a
+// token (RFC 8693 section 4.1). Prior actors of a delegation chain are
+// nested.
+type ActClaim struct {
+	Sub string    `json:"sub"`
+	Act *ActClaim `json:"act,omitempty"`
+}
+
+// CnfClaim carries the JWK SHA-256 thumbprint of the key a token is bound
+// to (RFC 9449 section 6.1).
+type CnfClaim struct {
+	Jkt string `json:"jkt"`
 }
 
 type IDTknClaims struct {
 	jwt.RegisteredClaims
-	SID string `json:"sid"`
+	SID      string           `json:"sid"`
+	Nonce    string           `json:"nonce,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	AtHash   string           `json:"at_hash,omitempty"`
+	UserClaims
 }
 
 type LogoutTknClaims struct {
@@ -18,3 +48,91 @@ type LogoutTknClaims struct {
 	Events map[string]struct{} `json:"events"`
 	SID    string              `json:"sid"`
 }
+
+// UserClaims are the standard claims describing the end-user (OIDC core
+// section 5.1).
+type UserClaims struct {
+	Name                string        `json:"name,omitempty"`
+	GivenName           string        `json:"given_name,omitempty"`
+	FamilyName          string        `json:"family_name,omitempty"`
+	Picture             string        `json:"picture,omitempty"`
+	UpdatedAt           int64         `json:"updated_at,omitempty"`
+	Email               string        `json:"email,omitempty"`
+	EmailVerified       *bool         `json:"email_verified,omitempty"`
+	PhoneNumber         string        `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool         `json:"phone_number_verified,omitempty"`
+	Address             *AddressClaim `json:"address,omitempty"`
+}
+
+type AddressClaim struct {
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"street_address,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Country       string `json:"country,omitempty"`
+}
+
+// newUserClaims releases the claims requested by the granted scopes, as
+// per OIDC core section 5.4.
+func newUserClaims(u sqlc.User, scopes []string) UserClaims {
+	var claims UserClaims
+
+	if hasScope(scopes, ScopeProfile) {
+		claims.Name = u.Name.String
+		claims.GivenName = u.GivenName.String
+		claims.FamilyName = u.FamilyName.String
+		claims.Picture = u.AvatarUrl.String
+		claims.UpdatedAt = u.CreatedAt.Unix()
+		if u.UpdatedAt.Valid {
+			claims.UpdatedAt = u.UpdatedAt.Time.Unix()
+		}
+	}
+
+	if hasScope(scopes, ScopeEmail) {
+		claims.Email = u.Email
+		claims.EmailVerified = &u.EmailVerified
+	}
+
+	// phone numbers are self-asserted and never verified
+	if hasScope(scopes, ScopePhone) && u.PhoneNumber.Valid {
+		verified := false
+		claims.PhoneNumber = u.PhoneNumber.String
+		claims.PhoneNumberVerified = &verified
+	}
+
+	if hasScope(scopes, ScopeAddress) {
+		addr := AddressClaim{
+			StreetAddress: u.StreetAddress.String,
+			Locality:      u.Locality.String,
+			Region:        u.Region.String,
+			PostalCode:    u.PostalCode.String,
+			Country:       u.Country.String,
+		}
+		addr.Formatted = addr.format()
+		if addr.Formatted != "" {
+			claims.Address = &addr
+		}
+	}
+
+	return claims
+}
+
+// format joins the address components, one line each for the street
+// address, the locality along with the region and postal code, and the
+// country.
+func (a AddressClaim) format() string {
+	var lines []string
+	if a.StreetAddress != "" {
+		lines = append(lines, a.StreetAddress)
+	}
+	locality := strings.Join(
+		strings.Fields(a.Locality+" "+a.Region+" "+a.PostalCode), " ")
+	if locality != "" {
+		lines = append(lines, locality)
+	}
+	if a.Country != "" {
+		lines = append(lines, a.Country)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,263 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ClientTypeConfidential = "confidential"
+	ClientTypePublic       = "public"
+)
+
+const (
+	AuthMethodSecretBasic   = "client_secret_basic"
+	AuthMethodSecretPost    = "client_secret_post"
+	AuthMethodPrivateKeyJWT = "private_key_jwt"
+	AuthMethodNone          = "none"
+)
+
+const clientAssertionTypeJWT = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// upper bound on the size of a key set fetched from a client's jwks_uri
+const maxClientJWKsSize = 1 << 16
+
+// algorithms accepted for client assertions
+var clientAssertionAlgs = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+var (
+	errInvalidClient      = errors.New("invalid client id or secret")
+	errMultipleAuthMethod = errors.New("multiple client authentication methods used")
+)
+
+// authenticateClient verifies the credentials presented by a client. It
+// supports HTTP basic auth (client_secret_basic), credentials in the form
+// body (client_secret_post), signed client assertions (private_key_jwt)
+// and public clients identified by their client id alone (none).
+func (a API) authenticateClient(c echo.Context) (*sqlc.Client, error) {
+	var (
+		method string
+		id     = c.FormValue("client_id")
+		secret = c.FormValue("client_secret")
+	)
+
+	assertion := c.FormValue("client_assertion")
+	basicID, basicSecret, hasBasic := c.Request().BasicAuth()
+
+	switch {
+	case hasBasic:
+		if secret != "" || assertion != "" {
+			return nil, errMultipleAuthMethod
+		}
+		// credentials are form-urlencoded before being placed in the
+		// header (RFC 6749 section 2.3.1)
+		var err error
+		basicID, err = url.QueryUnescape(basicID)
+		if err != nil {
+			return nil, errInvalidClient
+		}
+		basicSecret, err = url.QueryUnescape(basicSecret)
+		if err != nil {
+			return nil, errInvalidClient
+		}
+		if id != "" && id != basicID {
+			return nil, errInvalidClient
+		}
+		id, secret = basicID, basicSecret
+		method = AuthMethodSecretBasic
+	case assertion != "":
+		if secret != "" {
+			return nil, errMultipleAuthMethod
+		}
+		if c.FormValue("client_assertion_type") != clientAssertionTypeJWT {
+			return nil, errors.New("unsupported client assertion type")
+		}
+		// the client id is optional when using client assertions
+		if id == "" {
+			claims := new(jwt.RegisteredClaims)
+			_, _, err := jwt.NewParser().ParseUnverified(assertion, claims)
+			if err != nil {
+				return nil, errInvalidClient
+			}
+			id = claims.Subject
+		}
+		method = AuthMethodPrivateKeyJWT
+	case secret != "":
+		method = AuthMethodSecretPost
+	default:
+		method = AuthMethodNone
+	}
+
+	if id == "" {
+		return nil, errInvalidClient
+	}
+	client, err := a.DB.GetClient(c.Request().Context(), id)
+	if err != nil {
+		return nil, errInvalidClient
+	}
+
+	expected := client.TokenEndpointAuthMethod
+	if client.ClientType == ClientTypePublic {
+		expected = AuthMethodNone
+	}
+	if method != expected {
+		return nil, fmt.Errorf(
+			"client must authenticate using %s", expected)
+	}
+
+	switch method {
+	case AuthMethodNone:
+		return &client, nil
+	case AuthMethodPrivateKeyJWT:
+		aud := a.BaseURL + c.Path()
+		err := a.verifyClientAssertion(c.Request().Context(), client, assertion, aud)
+		if err != nil {
+			return nil, err
+		}
+		return &client, nil
+	}
+
+	if !client.SecretHash.Valid {
+		return nil, errInvalidClient
+	}
+	match, err := argon2id.ComparePasswordAndHash(secret, client.SecretHash.String)
+	if err != nil || !match {
+		return nil, errInvalidClient
+	}
+	return &client, nil
+}
+
+// verifyClientAssertion validates a client assertion (RFC 7523) against
+// the keys registered by the client. The audience may either be the
+// issuer, the token endpoint or the endpoint the assertion was sent to.
+// Assertions must carry a jti and are only accepted once (OpenID Connect
+// Core section 9).
+func (a API) verifyClientAssertion(ctx context.Context, client sqlc.Client, assertion, endpoint string) error {
+	keys, err := a.clientJWKs(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	claims := new(jwt.RegisteredClaims)
+	_, err = jwt.ParseWithClaims(
+		assertion, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			return keysFor(keys, t), nil
+		},
+		jwt.WithValidMethods(clientAssertionAlgs),
+		jwt.WithIssuer(client.ID),
+		jwt.WithSubject(client.ID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return errors.New("invalid client assertion")
+	}
+
+	var validAud bool
+	for _, aud := range claims.Audience {
+		if aud == a.BaseURL || aud == a.BaseURL+"/oauth/token" || aud == endpoint {
+			validAud = true
+			break
+		}
+	}
+	if !validAud {
+		return errors.New("invalid client assertion audience")
+	}
+
+	if claims.ID == "" {
+		return errors.New("client assertion must include jti")
+	}
+	err = a.useJTI(ctx, client.ID, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		if errors.Is(err, errReplayedJTI) {
+			return errors.New("client assertion has already been used")
+		}
+		return errors.New("failed to record client assertion")
+	}
+	return nil
+}
+
+// clientJWKs returns the public keys registered by the client, either
+// inline or by reference.
+func (a API) clientJWKs(ctx context.Context, client sqlc.Client) (*jose.JSONWebKeySet, error) {
+	if client.Jwks.Valid {
+		return ParseJWKs([]byte(client.Jwks.String))
+	}
+	if !client.JwksUri.Valid {
+		return nil, errors.New("client has no registered keys")
+	}
+
+	r, err := util.ReadPublicURL(ctx, client.JwksUri.String, maxClientJWKsSize)
+	if err != nil {
+		return nil, errors.New("failed to fetch client keys")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.New("failed to read client keys")
+	}
+	return ParseJWKs(data)
+}
+
+// ParseJWKs parses a JSON Web Key Set containing public signing keys.
+func ParseJWKs(data []byte) (*jose.JSONWebKeySet, error) {
+	keys := new(jose.JSONWebKeySet)
+	if err := json.Unmarshal(data, keys); err != nil {
+		return nil, errors.New("invalid JSON web key set")
+	}
+	if len(keys.Keys) == 0 {
+		return nil, errors.New("empty JSON web key set")
+	}
+	for _, k := range keys.Keys {
+		if !k.Valid() || !k.IsPublic() {
+			return nil, errors.New("key set must only contain public keys")
+		}
+		if k.Use != "" && k.Use != "sig" {
+			return nil, errors.New("key set must only contain signing keys")
+		}
+	}
+	return keys, nil
+}
+
+// keysFor returns the keys that may have signed the given token. When the
+// token does not name a key, every key in the set is tried.
+func keysFor(keys *jose.JSONWebKeySet, t *jwt.Token) jwt.VerificationKeySet {
+	kid, _ := t.Header["kid"].(string)
+	set := jwt.VerificationKeySet{}
+	for _, k := range keys.Keys {
+		if kid != "" && k.KeyID != kid {
+			continue
+		}
+		set.Keys = append(set.Keys, k.Key)
+	}
+	return set
+}
+
+// invalidClient responds with an invalid_client error as per RFC 6749
+// section 5.2.
+func invalidClient(c echo.Context, err error) error {
+	if _, _, ok := c.Request().BasicAuth(); ok {
+		c.Response().Header().Set("WWW-Authenticate", `Basic realm="ellipsis"`)
+	}
+	return c.JSON(http.StatusUnauthorized, tknResp{
+		Err:     "invalid_client",
+		ErrDesc: err.Error(),
+	})
+}
@@ -7,38 +7,105 @@ import (
 )
 
 type config struct {
-	Issuer                            string   `json:"issuer"`
-	AuthzEndp                         string   `json:"authorization_endpoint"`
-	TknEndp                           string   `json:"token_endpoint"`
-	UserinfoEndp                      string   `json:"userinfo_endpoint"`
-	JWKsURI                           string   `json:"jwks_uri"`
-	ScopesSupported                   []string `json:"scopes_supported"`
-	ResponseTypesSupported            []string `json:"response_types_supported"`
-	ResponseModesSupported            []string `json:"response_modes_supported"`
-	SubjectTypesSupported             []string `json:"subject_types_supported"`
-	IDTknSigningAlgValuesSupported    []string `json:"id_token_signing_alg_values_supported"`
-	TknEndpAuthMethodsSupported       []string `json:"token_endpoint_auth_methods_supported"`
-	ClaimsSupported                   []string `json:"claims_supported"`
-	RequestURIParamSupported          bool     `json:"request_uri_parameter_supported"`
-	RequestParamSupported             bool     `json:"request_parameter_supported"`
-	EndSessionEndpoint                string   `json:"end_session_endpoint"`
-	BackchannelLogoutSupported        bool     `json:"backchannel_logout_supported"`
-	BackchannelLogoutSessionSupported bool     `json:"backchannel_logout_session_supported"`
+	Issuer                                string   `json:"issuer"`
+	AuthzEndp                             string   `json:"authorization_endpoint"`
+	TknEndp                               string   `json:"token_endpoint"`
+	UserinfoEndp                          string   `json:"userinfo_endpoint"`
+	IntrospectionEndp                     string   `json:"introspection_endpoint"`
+	RevocationEndp                        string   `json:"revocation_endpoint"`
+	DeviceAuthzEndp                       string   `json:"device_authorization_endpoint"`
+	PushedAuthzRequestEndp                string   `json:"pushed_authorization_request_endpoint"`
+	RegistrationEndp                      string   `json:"registration_endpoint,omitempty"`
+	JWKsURI                               string   `json:"jwks_uri"`
+	ScopesSupported                       []string `json:"scopes_supported"`
+	ResponseTypesSupported                []string `json:"response_types_supported"`
+	ResponseModesSupported                []string `json:"response_modes_supported"`
+	GrantTypesSupported                   []string `json:"grant_types_supported"`
+	SubjectTypesSupported                 []string `json:"subject_types_supported"`
+	IDTknSigningAlgValuesSupported        []string `json:"id_token_signing_alg_values_supported"`
+	UserinfoSigningAlgValuesSupported     []string `json:"userinfo_signing_alg_values_supported"`
+	TknEndpAuthMethodsSupported           []string `json:"token_endpoint_auth_methods_supported"`
+	TknEndpAuthSigningAlgsSupported       []string `json:"token_endpoint_auth_signing_alg_values_supported"`
+	IntrospectionEndpAuthMethodsSupported []string `json:"introspection_endpoint_auth_methods_supported"`
+	RevocationEndpAuthMethodsSupported    []string `json:"revocation_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported         []string `json:"code_challenge_methods_supported"`
+	DPoPSigningAlgValuesSupported         []string `json:"dpop_signing_alg_values_supported"`
+	PromptValuesSupported                 []string `json:"prompt_values_supported"`
+	ClaimsSupported                       []string `json:"claims_supported"`
+	RequestURIParamSupported              bool     `json:"request_uri_parameter_supported"`
+	RequestParamSupported                 bool     `json:"request_parameter_supported"`
+	RequireRequestURIRegistration         bool     `json:"require_request_uri_registration"`
+	RequestObjectSigningAlgsSupported     []string `json:"request_object_signing_alg_values_supported"`
+	RequirePushedAuthzRequests            bool     `json:"require_pushed_authorization_requests"`
+	EndSessionEndpoint                    string   `json:"end_session_endpoint"`
+	BackchannelLogoutSupported            bool     `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported     bool     `json:"backchannel_logout_session_supported"`
+	FrontchannelLogoutSupported           bool     `json:"frontchannel_logout_supported"`
+	FrontchannelLogoutSessionSupported    bool     `json:"frontchannel_logout_session_supported"`
 }
 
 func (a API) configuration(c echo.Context) error {
+	var registrationEndp string
+	if a.Registration.Enable {
+		registrationEndp = a.BaseURL + "/oauth/register"
+	}
+
 	return c.JSON(http.StatusOK, config{
-		Issuer:                         a.BaseURL,
-		AuthzEndp:                      a.BaseURL + "/authorize",
-		TknEndp:                        a.BaseURL + "/oauth/token",
-		UserinfoEndp:                   a.BaseURL + "/userinfo",
-		JWKsURI:                        a.BaseURL + "/.well-known/jwks.json",
-		ScopesSupported:                []string{"openid", "profile"},
-		ResponseTypesSupported:         []string{"code"},
-		ResponseModesSupported:         []string{"query"},
-		SubjectTypesSupported:          []string{"public"},
-		IDTknSigningAlgValuesSupported: []string{"EdDSA"},
-		TknEndpAuthMethodsSupported:    []string{"client_secret_post"},
+		Issuer:                 a.BaseURL,
+		AuthzEndp:              a.BaseURL + "/authorize",
+		TknEndp:                a.BaseURL + "/oauth/token",
+		UserinfoEndp:           a.BaseURL + "/userinfo",
+		IntrospectionEndp:      a.BaseURL + "/oauth/introspect",
+		RevocationEndp:         a.BaseURL + "/oauth/revoke",
+		DeviceAuthzEndp:        a.BaseURL + "/oauth/device_authorization",
+		PushedAuthzRequestEndp: a.BaseURL + "/oauth/par",
+		RegistrationEndp:       registrationEndp,
+		JWKsURI:                a.BaseURL + "/.well-known/jwks.json",
+		ScopesSupported:        UserScopes,
+		ResponseTypesSupported: []string{"code"},
+		ResponseModesSupported: []string{
+			ResponseModeQuery,
+			ResponseModeFragment,
+			ResponseModeFormPost,
+		},
+		GrantTypesSupported: []string{
+			GrantTypeAuthzCode,
+			GrantTypeRefreshTkn,
+			GrantTypeClientCredentials,
+			GrantTypeDeviceCode,
+			GrantTypeTknExchange,
+		},
+		SubjectTypesSupported:             []string{"public"},
+		IDTknSigningAlgValuesSupported:    a.Keys.Algs(),
+		UserinfoSigningAlgValuesSupported: a.Keys.Algs(),
+		TknEndpAuthMethodsSupported: []string{
+			AuthMethodSecretBasic,
+			AuthMethodSecretPost,
+			AuthMethodPrivateKeyJWT,
+			AuthMethodNone,
+		},
+		TknEndpAuthSigningAlgsSupported: clientAssertionAlgs,
+		IntrospectionEndpAuthMethodsSupported: []string{
+			AuthMethodSecretBasic,
+			AuthMethodSecretPost,
+			AuthMethodPrivateKeyJWT,
+		},
+		RevocationEndpAuthMethodsSupported: []string{
+			AuthMethodSecretBasic,
+			AuthMethodSecretPost,
+			AuthMethodPrivateKeyJWT,
+			AuthMethodNone,
+		},
+		CodeChallengeMethodsSupported: []string{
+			CodeChallengeMethodS256,
+			CodeChallengeMethodPlain,
+		},
+		DPoPSigningAlgValuesSupported: dpopSigningAlgs,
+		PromptValuesSupported: []string{
+			PromptNone,
+			PromptLogin,
+			PromptConsent,
+		},
 		ClaimsSupported: []string{
 			"iss",
 			"aud",
@@ -46,11 +113,29 @@ func (a API) configuration(c echo.Context) error {
 			"iat",
 			"exp",
 			"sid",
+			"nonce",
+			"auth_time",
+			"at_hash",
+			"name",
+			"given_name",
+			"family_name",
+			"picture",
+			"updated_at",
+			"email",
+			"email_verified",
+			"phone_number",
+			"phone_number_verified",
+			"address",
 		},
-		RequestURIParamSupported:          false,
-		RequestParamSupported:             false,
-		EndSessionEndpoint:                a.BaseURL + "/oidc/logout",
-		BackchannelLogoutSupported:        true,
-		BackchannelLogoutSessionSupported: true,
+		RequestURIParamSupported:           true,
+		RequestParamSupported:              true,
+		RequireRequestURIRegistration:      true,
+		RequestObjectSigningAlgsSupported:  requestObjectAlgs,
+		RequirePushedAuthzRequests:         false,
+		EndSessionEndpoint:                 a.BaseURL + "/oidc/logout",
+		BackchannelLogoutSupported:         true,
+		BackchannelLogoutSessionSupported:  true,
+		FrontchannelLogoutSupported:        true,
+		FrontchannelLogoutSessionSupported: true,
 	})
 }
@@ -0,0 +1,501 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/api/apierr"
+	"github.com/murtaza-u/ellipsis/api/middleware"
+	"github.com/murtaza-u/ellipsis/api/render"
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+	"github.com/murtaza-u/ellipsis/view"
+	"github.com/murtaza-u/ellipsis/view/layout"
+
+	"github.com/labstack/echo/v4"
+)
+
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	DeviceCodeStatusPending  = "pending"
+	DeviceCodeStatusApproved = "approved"
+	DeviceCodeStatusDenied   = "denied"
+)
+
+const (
+	deviceCodeExpiration = time.Minute * 10
+	// minimum number of seconds between polls (RFC 8628 section 3.2)
+	devicePollInterval = 5
+)
+
+// user codes avoid vowels and easily confused characters (RFC 8628
+// section 6.1)
+const userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+
+var errInvalidUserCode = errors.New("invalid or expired code")
+
+type deviceAuthzParams struct {
+	Scope string `form:"scope"`
+}
+
+type deviceAuthzResp struct {
+	Err                     string `json:"error,omitempty"`
+	ErrDesc                 string `json:"error_description,omitempty"`
+	DeviceCode              string `json:"device_code,omitempty"`
+	UserCode                string `json:"user_code,omitempty"`
+	VerificationURI         string `json:"verification_uri,omitempty"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in,omitempty"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// DeviceAuthorization starts the device authorization grant (RFC 8628)
+// for devices that can not receive a browser redirect.
+func (a API) DeviceAuthorization(c echo.Context) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+
+	params := new(deviceAuthzParams)
+	if err := c.Bind(params); err != nil {
+		return c.JSON(http.StatusBadRequest, deviceAuthzResp{
+			Err:     "invalid_request",
+			ErrDesc: "failed to parse form data",
+		})
+	}
+	if err := validateUserScopes(params.Scope, nil); err != nil {
+		return c.JSON(http.StatusBadRequest, deviceAuthzResp{
+			Err:     "invalid_scope",
+			ErrDesc: err.Error(),
+		})
+	}
+
+	deviceCode, err := util.GenerateRandom(50)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, deviceAuthzResp{
+			Err:     "server_error",
+			ErrDesc: "failed to generate device code",
+		})
+	}
+	userCode, err := newUserCode()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, deviceAuthzResp{
+			Err:     "server_error",
+			ErrDesc: "failed to generate user code",
+		})
+	}
+
+	_, err = a.DB.CreateDeviceCode(
+		c.Request().Context(),
+		sqlc.CreateDeviceCodeParams{
+			ID:        hashTkn(deviceCode),
+			UserCode:  userCode,
+			ClientID:  client.ID,
+			Scopes:    params.Scope,
+			ExpiresAt: time.Now().Add(deviceCodeExpiration),
+		},
+	)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, deviceAuthzResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	verificationURI := a.BaseURL + "/device"
+	return c.JSON(http.StatusOK, deviceAuthzResp{
+		DeviceCode:      deviceCode,
+		UserCode:        formatUserCode(userCode),
+		VerificationURI: verificationURI,
+		VerificationURIComplete: fmt.Sprintf(
+			"%s?user_code=%s",
+			verificationURI,
+			url.QueryEscape(formatUserCode(userCode)),
+		),
+		ExpiresIn: int(deviceCodeExpiration.Seconds()),
+		Interval:  devicePollInterval,
+	})
+}
+
+func (a API) device(c echo.Context) error {
+	u, err := a.deviceUser(c)
+	if err != nil {
+		return err
+	}
+
+	userCode := c.QueryParam("user_code")
+	if userCode == "" {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Device | Ellipsis",
+				view.Device(*u, "", nil),
+			),
+		})
+	}
+
+	dc, err := a.pendingDeviceCode(c, userCode)
+	if err != nil {
+		if errors.Is(err, errInvalidUserCode) {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: layout.Base(
+					"Device | Ellipsis",
+					view.Device(*u, userCode, err),
+				),
+				Status: http.StatusBadRequest,
+			})
+		}
+		return err
+	}
+
+	client, err := a.DB.GetClient(c.Request().Context(), dc.ClientID)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read client from db: %w", err),
+			layout.Base(
+				"Device | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+
+	return render.Do(render.Params{
+		Ctx: c,
+		Component: layout.Base(
+			"Authorize Device | Ellipsis",
+			view.DeviceConsent(formatUserCode(dc.UserCode), *u, client, strings.Fields(dc.Scopes)),
+		),
+	})
+}
+
+type deviceConsentParams struct {
+	Consent  string `form:"consent"`
+	UserCode string `form:"user_code"`
+}
+
+func (a API) deviceConsent(c echo.Context) error {
+	u, err := a.deviceUser(c)
+	if err != nil {
+		return err
+	}
+
+	form := new(deviceConsentParams)
+	if err := c.Bind(form); err != nil {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Device | Ellipsis",
+				view.Error(
+					"Failed to parse form",
+					http.StatusBadRequest,
+				),
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	dc, err := a.pendingDeviceCode(c, form.UserCode)
+	if err != nil {
+		if errors.Is(err, errInvalidUserCode) {
+			return render.Do(render.Params{
+				Ctx: c,
+				Component: layout.Base(
+					"Device | Ellipsis",
+					view.Device(*u, form.UserCode, err),
+				),
+				Status: http.StatusBadRequest,
+			})
+		}
+		return err
+	}
+
+	var authTime time.Time
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		authTime = ctx.AuthTime
+	}
+
+	granted := form.Consent == "granted"
+	status := DeviceCodeStatusDenied
+	if granted {
+		status = DeviceCodeStatusApproved
+	}
+
+	res, err := a.DB.UpdateDeviceCodeStatus(
+		c.Request().Context(),
+		sqlc.UpdateDeviceCodeStatusParams{
+			Status:   status,
+			UserID:   sql.NullString{String: u.ID, Valid: true},
+			AuthTime: sql.NullTime{Time: authTime, Valid: !authTime.IsZero()},
+			UserCode: dc.UserCode,
+		},
+	)
+	if err != nil {
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to update device code in db: %w", err),
+			layout.Base(
+				"Device | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+	// someone else acted on the code in the meantime
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Device | Ellipsis",
+				view.Device(*u, form.UserCode, errInvalidUserCode),
+			),
+			Status: http.StatusBadRequest,
+		})
+	}
+
+	return render.Do(render.Params{
+		Ctx: c,
+		Component: layout.Base(
+			"Device | Ellipsis",
+			view.DeviceResult(*u, granted),
+		),
+	})
+}
+
+// deviceUser returns the logged in user approving a device.
+func (a API) deviceUser(c echo.Context) (*sqlc.User, error) {
+	var userID string
+	if ctx, ok := c.(middleware.CtxWithAuthInfo); ok {
+		userID = ctx.UserID
+	}
+	if userID == "" {
+		return nil, apierr.New(
+			http.StatusInternalServerError,
+			errors.New("missing auth info in context"),
+			layout.Base(
+				"Device | Ellipsis",
+				view.Error(
+					"An internal error occured",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+	u, err := a.DB.GetUser(c.Request().Context(), userID)
+	if err != nil {
+		return nil, apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read user from db: %w", err),
+			layout.Base(
+				"Device | Ellipsis",
+				view.Error(
+					"An internal error occured",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+	return &u, nil
+}
+
+// pendingDeviceCode looks up a device code awaiting the user's decision.
+// errInvalidUserCode is returned if no such code exists.
+func (a API) pendingDeviceCode(c echo.Context, userCode string) (*sqlc.DeviceCode, error) {
+	dc, err := a.DB.GetDeviceCodeByUserCode(
+		c.Request().Context(),
+		normalizeUserCode(userCode),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInvalidUserCode
+		}
+		return nil, apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read device code from db: %w", err),
+			layout.Base(
+				"Device | Ellipsis",
+				view.Error(
+					"Database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
+	}
+	if dc.Status != DeviceCodeStatusPending || time.Until(dc.ExpiresAt) <= 0 {
+		return nil, errInvalidUserCode
+	}
+	return &dc, nil
+}
+
+func (a API) deviceCodeGrant(c echo.Context, params *tknParams) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+	if client.RequireDpop && params.jkt == "" {
+		return missingDPoPProof(c)
+	}
+
+	if params.DeviceCode == "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "missing device code",
+		})
+	}
+
+	ctx := c.Request().Context()
+	dc, err := a.DB.GetDeviceCode(ctx, hashTkn(params.DeviceCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "invalid_grant",
+				ErrDesc: "invalid device code",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	if dc.ClientID != client.ID {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "device code was issued to another client",
+		})
+	}
+	if time.Until(dc.ExpiresAt) <= 0 {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "expired_token",
+			ErrDesc: "device code expired",
+		})
+	}
+
+	// clients polling faster than allowed must wait an additional 5
+	// seconds from now on (RFC 8628 section 3.5)
+	interval := dc.PollInterval
+	now := time.Now()
+	tooFast := dc.LastPolledAt.Valid &&
+		now.Sub(dc.LastPolledAt.Time) < time.Second*time.Duration(interval)
+	if tooFast {
+		interval += devicePollInterval
+	}
+	err = a.DB.UpdateDeviceCodePoll(ctx, sqlc.UpdateDeviceCodePollParams{
+		ID:           dc.ID,
+		PollInterval: interval,
+		LastPolledAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	if tooFast {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "slow_down",
+			ErrDesc: fmt.Sprintf("poll at most every %d seconds", interval),
+		})
+	}
+
+	switch dc.Status {
+	case DeviceCodeStatusPending:
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "authorization_pending",
+			ErrDesc: "user has not yet approved the device",
+		})
+	case DeviceCodeStatusDenied:
+		if _, err := a.DB.DeleteDeviceCode(ctx, dc.ID); err != nil {
+			return c.JSON(http.StatusInternalServerError, tknResp{
+				Err:     "server_error",
+				ErrDesc: "database operation failed",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "access_denied",
+			ErrDesc: "user denied the authorization request",
+		})
+	}
+
+	// the device flow has no front channel, hence the resource can only
+	// be indicated at the token endpoint
+	resource := resourceParam(params.Resource, params.Audience)
+	if _, err := a.resolveResource(ctx, resource); err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: err.Error(),
+		})
+	}
+
+	// invalidate device code. Only the poll that removed it gets tokens.
+	res, err := a.DB.DeleteDeviceCode(ctx, dc.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "device code has already been used",
+		})
+	}
+
+	fingerprint := util.ParseUA(c.Request().Header.Get("User-Agent"))
+	return a.issueSessionTkns(c, client, grant{
+		UserID:   dc.UserID.String,
+		Scope:    dc.Scopes,
+		AuthTime: dc.AuthTime,
+		Os:       fingerprint.OS,
+		Browser:  fingerprint.Browser,
+		Resource: sql.NullString{String: resource, Valid: resource != ""},
+		Jkt:      params.jkt,
+	})
+}
+
+func newUserCode() (string, error) {
+	ret := make([]byte, 8)
+	for i := range ret {
+		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeChars))))
+		if err != nil {
+			return "", err
+		}
+		ret[i] = userCodeChars[num.Int64()]
+	}
+	return string(ret), nil
+}
+
+// normalizeUserCode strips the separator and any whitespace users may
+// type along with the code.
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+func formatUserCode(code string) string {
+	if len(code) != 8 {
+		return code
+	}
+	return code[:4] + "-" + code[4:]
+}
@@ -0,0 +1,167 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/db"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// how long after being issued a DPoP proof is accepted
+	dpopProofLifetime = time.Minute * 5
+	// allowed clock skew for proofs issued by clients running ahead
+	dpopProofLeeway = time.Second * 30
+)
+
+// algorithms accepted for DPoP proofs
+var dpopSigningAlgs = clientAssertionAlgs
+
+var errInvalidDPoPProof = errors.New("invalid DPoP proof")
+
+type dpopClaims struct {
+	Htm string `json:"htm"`
+	Htu string `json:"htu"`
+	Ath string `json:"ath,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// dpopProof validates the DPoP proof of a request as per RFC 9449 section
+// 4.3 and returns the JWK SHA-256 thumbprint of the key it was signed
+// with. An empty thumbprint without an error means the request carried no
+// proof. When an access token is given, the proof must be bound to it.
+// Every proof is only accepted once.
+func (a API) dpopProof(c echo.Context, accessTkn string) (string, error) {
+	proofs := c.Request().Header.Values("DPoP")
+	switch len(proofs) {
+	case 0:
+		return "", nil
+	case 1:
+	default:
+		return "", fmt.Errorf("%w: multiple proofs", errInvalidDPoPProof)
+	}
+
+	var jwk jose.JSONWebKey
+	claims := new(dpopClaims)
+	_, err := jwt.ParseWithClaims(
+		proofs[0], claims,
+		func(t *jwt.Token) (interface{}, error) {
+			if typ, _ := t.Header["typ"].(string); typ != "dpop+jwt" {
+				return nil, errors.New("invalid typ")
+			}
+			raw, err := json.Marshal(t.Header["jwk"])
+			if err != nil {
+				return nil, err
+			}
+			if err := jwk.UnmarshalJSON(raw); err != nil {
+				return nil, err
+			}
+			if !jwk.Valid() || !jwk.IsPublic() {
+				return nil, errors.New("jwk must be a public key")
+			}
+			return jwk.Key, nil
+		},
+		jwt.WithValidMethods(dpopSigningAlgs),
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid signature or header", errInvalidDPoPProof)
+	}
+
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return "", fmt.Errorf("%w: missing jti or iat", errInvalidDPoPProof)
+	}
+	iat := claims.IssuedAt.Time
+	if time.Since(iat) > dpopProofLifetime || time.Until(iat) > dpopProofLeeway {
+		return "", fmt.Errorf("%w: proof expired or issued in the future", errInvalidDPoPProof)
+	}
+	if claims.Htm != c.Request().Method {
+		return "", fmt.Errorf("%w: htm does not match the request method", errInvalidDPoPProof)
+	}
+	if !a.matchesHtu(c, claims.Htu) {
+		return "", fmt.Errorf("%w: htu does not match the request URI", errInvalidDPoPProof)
+	}
+	if accessTkn != "" {
+		sum := sha256.Sum256([]byte(accessTkn))
+		if claims.Ath != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", fmt.Errorf("%w: ath does not match the access token", errInvalidDPoPProof)
+		}
+	}
+
+	tp, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidDPoPProof, err.Error())
+	}
+	jkt := base64.RawURLEncoding.EncodeToString(tp)
+
+	// jti values are only unique per key. The primary key rejects proofs
+	// that have already been used.
+	_, err = a.DB.CreateDPoPProof(c.Request().Context(), sqlc.CreateDPoPProofParams{
+		ID:        hashTkn(jkt + "." + claims.ID),
+		ExpiresAt: iat.Add(dpopProofLifetime),
+	})
+	if err != nil {
+		if db.IsDuplicateKey(err) {
+			return "", fmt.Errorf("%w: proof has already been used", errInvalidDPoPProof)
+		}
+		return "", err
+	}
+
+	return jkt, nil
+}
+
+// matchesHtu reports whether the htu claim of a proof names the endpoint
+// the request was sent to. The query and fragment are ignored (RFC 9449
+// section 4.3).
+func (a API) matchesHtu(c echo.Context, htu string) bool {
+	u, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	base, err := url.Parse(a.BaseURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, base.Scheme) &&
+		strings.EqualFold(u.Host, base.Host) &&
+		u.Path == base.Path+c.Request().URL.Path
+}
+
+// missingDPoPProof responds to token requests of clients that require
+// DPoP but did not present a proof.
+func missingDPoPProof(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, tknResp{
+		Err:     "invalid_dpop_proof",
+		ErrDesc: "client requires DPoP-bound tokens",
+	})
+}
+
+// cnfClaim returns the confirmation claim binding a token to the DPoP key
+// with the given thumbprint, if any.
+func cnfClaim(jkt string) *CnfClaim {
+	if jkt == "" {
+		return nil
+	}
+	return &CnfClaim{Jkt: jkt}
+}
+
+// tknType returns the type of access tokens, depending on whether they
+// are bound to a DPoP key.
+func tknType(jkt string) string {
+	if jkt == "" {
+		return schemeBearer
+	}
+	return schemeDPoP
+}
@@ -0,0 +1,179 @@
+package oidc
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	GrantTypeTknExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TknTypeAccessTkn     = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// tknExchangeGrant exchanges an access token for a downscoped one
+// addressed to another API as per RFC 8693. The authenticated client is
+// recorded as the actor, and may only exchange tokens for the APIs an
+// admin allowed through a token exchange policy.
+func (a API) tknExchangeGrant(c echo.Context, params *tknParams) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+	if client.ClientType == ClientTypePublic {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "unauthorized_client",
+			ErrDesc: "public clients can not exchange tokens",
+		})
+	}
+	if client.RequireDpop && params.jkt == "" {
+		return missingDPoPProof(c)
+	}
+
+	if params.SubjectTkn == "" || params.SubjectTknType == "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "missing subject_token or subject_token_type",
+		})
+	}
+	if params.SubjectTknType != TknTypeAccessTkn {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "unsupported subject_token_type",
+		})
+	}
+	// the authenticated client is always the actor
+	if params.ActorTkn != "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "actor_token is not supported",
+		})
+	}
+	if params.RequestedTknType != "" && params.RequestedTknType != TknTypeAccessTkn {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "unsupported requested_token_type",
+		})
+	}
+
+	ctx := c.Request().Context()
+
+	resource := resourceParam(params.Resource, params.Audience)
+	if resource == "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: "missing resource or audience",
+		})
+	}
+	api, err := a.resolveResource(ctx, resource)
+	if err != nil {
+		if errors.Is(err, errInvalidTarget) {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "invalid_target",
+				ErrDesc: err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	policy, err := a.DB.GetTokenExchangePolicy(ctx, sqlc.GetTokenExchangePolicyParams{
+		ClientID:      client.ID,
+		ApiResourceID: api.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "unauthorized_client",
+				ErrDesc: "client may not exchange tokens for this resource",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	subject, err := a.resolveAccessTkn(ctx, params.SubjectTkn)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "invalid or inactive subject_token",
+		})
+	}
+	// a DPoP-bound subject token may only be exchanged by whoever holds
+	// its key, proven through the DPoP proof of this request
+	if subject.Cnf != nil && subject.Cnf.Jkt != params.jkt {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "subject_token is bound to another DPoP key",
+		})
+	}
+
+	// defaults to the scopes of the subject token that the API defines
+	// and the policy allows
+	var allowed []string
+	for _, s := range subject.Scopes {
+		if !hasScope(apiScopes(api), s) {
+			continue
+		}
+		if policy.Scopes.Valid && !hasScope(strings.Fields(policy.Scopes.String), s) {
+			continue
+		}
+		allowed = append(allowed, s)
+	}
+	scopes := allowed
+	if params.Scope != "" {
+		scopes = strings.Fields(params.Scope)
+		for _, s := range scopes {
+			if !hasScope(allowed, s) {
+				return c.JSON(http.StatusBadRequest, tknResp{
+					Err:     "invalid_scope",
+					ErrDesc: "scope not granted by the subject_token or allowed by policy",
+				})
+			}
+		}
+	}
+	if len(scopes) == 0 {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_scope",
+			ErrDesc: "no scope of the subject_token can be exchanged for this resource",
+		})
+	}
+
+	claims := a.newAccessTknClaims(subject.UserID, client, api, subject.SID, scopes)
+	if subject.UserID == "" {
+		claims.Subject = subject.Subject
+	}
+	claims.Act = &ActClaim{Sub: client.ID, Act: subject.Act}
+	claims.Cnf = cnfClaim(params.jkt)
+	// never outlive the subject token
+	if subject.ExpiresAt.Before(claims.ExpiresAt.Time) {
+		claims.ExpiresAt = jwt.NewNumericDate(subject.ExpiresAt.Time)
+	}
+
+	accessTknStr, err := a.issueAccessTkn(ctx, client, claims)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "failed to generate access token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, tknResp{
+		AccessTkn:     accessTknStr,
+		IssuedTknType: TknTypeAccessTkn,
+		TknType:       tknType(params.jkt),
+		ExpiresIn:     int(time.Until(claims.ExpiresAt.Time).Seconds()),
+		Scope:         strings.Join(scopes, " "),
+	})
+}
@@ -0,0 +1,15 @@
+package oidc
+
+import (
+	"net/url"
+)
+
+// FrontchannelLogoutURL returns the front-channel logout URI of a client
+// with the iss and sid parameters attached, as per OpenID Connect
+// Front-Channel Logout 1.0 section 2.
+func FrontchannelLogoutURL(uri, iss, sid string) string {
+	return withQuery(uri, url.Values{
+		"iss": {iss},
+		"sid": {sid},
+	})
+}
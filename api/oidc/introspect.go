@@ -0,0 +1,117 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+var errInactiveTkn = errors.New("invalid or expired access token")
+
+type introspectParams struct {
+	Tkn         string `form:"token"`
+	TknTypeHint string `form:"token_type_hint"`
+}
+
+type introspectResp struct {
+	Active   bool      `json:"active"`
+	Scope    string    `json:"scope,omitempty"`
+	ClientID string    `json:"client_id,omitempty"`
+	Sub      string    `json:"sub,omitempty"`
+	Aud      []string  `json:"aud,omitempty"`
+	Exp      int64     `json:"exp,omitempty"`
+	SID      string    `json:"sid,omitempty"`
+	Act      *ActClaim `json:"act,omitempty"`
+	Cnf      *CnfClaim `json:"cnf,omitempty"`
+}
+
+// Introspect reports whether an access token is still active as per RFC
+// 7662. Unlike a plain signature check, it also verifies that the session
+// and client the token was issued for still exist.
+func (a API) Introspect(c echo.Context) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+	if client.ClientType == ClientTypePublic {
+		return invalidClient(c, errors.New("public clients can not introspect tokens"))
+	}
+
+	params := new(introspectParams)
+	if err := c.Bind(params); err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "failed to parse form data",
+		})
+	}
+	if params.Tkn == "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "missing token",
+		})
+	}
+
+	claims, err := a.resolveAccessTkn(c.Request().Context(), params.Tkn)
+	if err != nil {
+		return c.JSON(http.StatusOK, introspectResp{Active: false})
+	}
+
+	return c.JSON(http.StatusOK, introspectResp{
+		Active:   true,
+		Scope:    strings.Join(claims.Scopes, " "),
+		ClientID: claims.ClientID,
+		Sub:      claims.Subject,
+		Aud:      claims.Audience,
+		Exp:      claims.ExpiresAt.Unix(),
+		SID:      claims.SID,
+		Act:      claims.Act,
+		Cnf:      claims.Cnf,
+	})
+}
+
+// resolveAccessTkn validates an access token and cross-checks it against
+// the database. A token is only considered active while its client and,
+// for tokens issued to a user, its session still exist. Opaque tokens are
+// looked up instead of being parsed.
+func (a API) resolveAccessTkn(ctx context.Context, tknStr string) (*AccessTknClaims, error) {
+	claims := new(AccessTknClaims)
+	if isOpaqueTkn(tknStr) {
+		var err error
+		claims, err = a.resolveOpaqueAccessTkn(ctx, tknStr)
+		if err != nil {
+			return nil, errInactiveTkn
+		}
+	} else {
+		_, err := jwt.ParseWithClaims(
+			tknStr, claims,
+			a.keyFunc,
+			jwt.WithValidMethods(signingAlgs),
+			jwt.WithIssuer(a.BaseURL),
+			jwt.WithExpirationRequired(),
+		)
+		if err != nil {
+			return nil, errInactiveTkn
+		}
+	}
+
+	if _, err := a.DB.GetClient(ctx, claims.ClientID); err != nil {
+		return nil, errInactiveTkn
+	}
+
+	if claims.SID != "" {
+		sess, err := a.DB.GetSession(ctx, claims.SID)
+		if err != nil {
+			return nil, errInactiveTkn
+		}
+		if time.Until(sess.ExpiresAt) <= 0 || sess.UserID != claims.UserID {
+			return nil, errInactiveTkn
+		}
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,26 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/db"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+)
+
+var errReplayedJTI = errors.New("jti has already been used")
+
+// useJTI records the jti of a JWT issued by iss until the JWT expires,
+// so that the JWT is only ever accepted once. The primary key makes the
+// insert the single-use gate. jti values are only unique per issuer.
+func (a API) useJTI(ctx context.Context, iss, jti string, exp time.Time) error {
+	_, err := a.DB.CreateUsedJTI(ctx, sqlc.CreateUsedJTIParams{
+		ID:        hashTkn(iss + "." + jti),
+		ExpiresAt: exp,
+	})
+	if db.IsDuplicateKey(err) {
+		return errReplayedJTI
+	}
+	return err
+}
@@ -1,36 +1,70 @@
 package oidc
 
 import (
-	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/murtaza-u/ellipsis/internal/conf"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-type JWK struct {
-	Kty string `json:"kty"`
-	Kid string `json:"kid"`
-	Alg string `json:"alg"`
-	Use string `json:"use"`
-	Crv string `json:"crv"`
-	X   string `json:"x"`
+// algorithms tokens may be signed with, provided the key store holds an
+// active key for them
+var signingAlgs = []string{conf.AlgEdDSA, conf.AlgRS256, conf.AlgES256}
+
+// DefaultSigningAlg is used for clients that did not pick an algorithm.
+const DefaultSigningAlg = conf.AlgEdDSA
+
+func (a API) JWKs(c echo.Context) error {
+	var keys jose.JSONWebKeySet
+	for _, k := range a.Keys.Published() {
+		keys.Keys = append(keys.Keys, jose.JSONWebKey{
+			Key:       k.Pub,
+			KeyID:     k.ID,
+			Algorithm: k.Alg,
+			Use:       "sig",
+		})
+	}
+	return c.JSON(http.StatusOK, keys)
 }
 
-type Keys struct {
-	JWKs []JWK `json:"keys"`
+func (a API) sign(alg string, claims jwt.Claims) (string, error) {
+	return Sign(a.Keys, alg, claims)
 }
 
-func (a API) JWKs(c echo.Context) error {
-	return c.JSON(http.StatusOK, Keys{
-		JWKs: []JWK{
-			{
-				Kty: "OKP",
-				Kid: "ed25519-key-1",
-				Alg: "EdDSA",
-				Use: "sig",
-				Crv: "Ed25519",
-				X:   base64.RawStdEncoding.EncodeToString(a.Key.Pub),
-			},
-		},
-	})
+// Sign signs the claims using the active key for the given algorithm.
+func Sign(keys conf.KeySet, alg string, claims jwt.Claims) (string, error) {
+	k, ok := keys.Signer(alg)
+	if !ok {
+		return "", fmt.Errorf("no active signing key for %s", alg)
+	}
+	tkn := jwt.NewWithClaims(jwt.GetSigningMethod(alg), claims)
+	tkn.Header["kid"] = k.ID
+	return tkn.SignedString(k.Priv)
+}
+
+// keyFunc selects the verification key by the kid header. Tokens signed
+// before key ids were introduced carry none, in which case every
+// published key is tried.
+func (a API) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		set := jwt.VerificationKeySet{}
+		for _, k := range a.Keys.Published() {
+			set.Keys = append(set.Keys, k.Pub)
+		}
+		return set, nil
+	}
+	k, ok := a.Keys.Lookup(kid)
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if t.Method.Alg() != k.Alg {
+		return nil, errors.New("signing algorithm mismatch")
+	}
+	return k.Pub, nil
 }
@@ -1,15 +1,19 @@
 package oidc
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/murtaza-u/ellipsis/api/apierr"
 	"github.com/murtaza-u/ellipsis/api/render"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
 	"github.com/murtaza-u/ellipsis/view"
 	"github.com/murtaza-u/ellipsis/view/layout"
 
@@ -57,9 +61,7 @@ func (a API) Logout(c echo.Context) error {
 	claims := new(IDTknClaims)
 	_, err := jwt.ParseWithClaims(
 		q.IDTkn, claims,
-		func(t *jwt.Token) (interface{}, error) {
-			return a.Key.Pub, nil
-		},
+		a.keyFunc,
 	)
 	if err != nil {
 		return render.Do(render.Params{
@@ -90,6 +92,17 @@ func (a API) Logout(c echo.Context) error {
 				Status: http.StatusBadRequest,
 			})
 		}
+		return apierr.New(
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to read session from db: %w", err),
+			layout.Base(
+				"Logout | Ellipsis",
+				view.Error(
+					"database operation failed",
+					http.StatusInternalServerError,
+				),
+			),
+		)
 	}
 
 	if q.ClientID != "" && q.ClientID != sess.ClientID {
@@ -145,11 +158,37 @@ func (a API) Logout(c echo.Context) error {
 		})
 	}
 
-	err = a.DB.DeleteSession(c.Request().Context(), claims.SID)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	ctx := c.Request().Context()
+
+	// end the browser session the app session was spawned from, signing
+	// the user out of every app. App sessions without a parent are ended
+	// on their own.
+	var frames []string
+	if sess.ParentID.Valid {
+		frames, err = EndSession(ctx, a.DB, a.BaseURL, sess.ParentID.String)
+		cookie, cerr := c.Cookie("auth_session")
+		if cerr == nil && cookie.Value == sess.ParentID.String {
+			c.SetCookie(&http.Cookie{
+				Name:    "auth_session",
+				Value:   "",
+				Expires: time.Unix(0, 0),
+				Path:    "/",
+			})
+		}
+	} else {
+		err = a.DB.DeleteSession(ctx, claims.SID)
+		if err != nil {
+			err = fmt.Errorf("failed to delete session from db: %w", err)
+		}
+		if sess.FrontchannelLogoutUri.Valid {
+			frames = append(frames, FrontchannelLogoutURL(
+				sess.FrontchannelLogoutUri.String, a.BaseURL, sess.ID))
+		}
+	}
+	if err != nil {
 		return apierr.New(
 			http.StatusInternalServerError,
-			fmt.Errorf("failed to delete session from db: %w", err),
+			fmt.Errorf("failed to end session: %w", err),
 			layout.Base(
 				"Logout | Ellipsis",
 				view.Error(
@@ -161,8 +200,56 @@ func (a API) Logout(c echo.Context) error {
 	}
 
 	if q.State != "" {
-		redirectTo += fmt.Sprintf("?state=%s", url.QueryEscape(q.State))
+		redirectTo = withQuery(redirectTo, url.Values{"state": {q.State}})
+	}
+
+	if len(frames) != 0 {
+		return render.Do(render.Params{
+			Ctx: c,
+			Component: layout.Base(
+				"Logout | Ellipsis",
+				view.FrontchannelLogout(frames, redirectTo),
+			),
+		})
 	}
 
 	return c.Redirect(http.StatusFound, redirectTo)
 }
+
+// EndSession terminates the browser session sid along with every client
+// session spawned from it. Apps supporting back-channel logout are
+// notified through the outbox, while the returned front-channel logout
+// URIs are to be loaded by the user agent.
+func EndSession(ctx context.Context, db *sqlc.Queries, iss, sid string) ([]string, error) {
+	parentID := sql.NullString{String: sid, Valid: true}
+	children, err := db.GetChildSessionsWithClient(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read child sessions from db: %w", err)
+	}
+
+	var frames []string
+	for _, cs := range children {
+		if cs.BackchannelLogoutUrl.Valid {
+			// the session is revoked regardless of the app being notified
+			err := EnqueueBackchannelLogout(ctx, db, cs.ClientID, cs.UserID, cs.ID)
+			if err != nil {
+				slog.Error("failed to schedule back-channel logout",
+					"session_id", cs.ID, "error", err)
+			}
+		}
+		if cs.FrontchannelLogoutUri.Valid {
+			frames = append(frames, FrontchannelLogoutURL(
+				cs.FrontchannelLogoutUri.String, iss, cs.ID))
+		}
+	}
+
+	err = db.DeleteChildSessions(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete child sessions from db: %w", err)
+	}
+	err = db.DeleteSession(ctx, sid)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to delete session from db: %w", err)
+	}
+	return frames, nil
+}
@@ -13,20 +13,37 @@ import (
 )
 
 const (
-	ScopeOIDC    = "openid"
-	ScopeProfile = "profile"
+	ScopeOIDC          = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopePhone         = "phone"
+	ScopeAddress       = "address"
+	ScopeOfflineAccess = "offline_access"
 )
 
+// UserScopes are granted by an end-user and can never be issued to a
+// client acting on its own behalf.
+var UserScopes = []string{
+	ScopeOIDC,
+	ScopeProfile,
+	ScopeEmail,
+	ScopePhone,
+	ScopeAddress,
+	ScopeOfflineAccess,
+}
+
 type API struct {
 	Config
 }
 
 type Config struct {
 	DB        *sqlc.Queries
-	Key       conf.Key
+	Keys      conf.KeySet
 	Providers conf.Providers
 	BaseURL   string
 	FS        fs.Storage
+
+	Registration conf.Registration
 }
 
 func New(c Config) (*API, error) {
@@ -37,12 +54,19 @@ func (a API) Register(app *echo.Echo) error {
 	app.GET("/.well-known/openid-configuration", a.configuration)
 
 	auth := middleware.NewAuthMiddleware(a.DB)
-	app.GET("/authorize", a.authorize, auth.Required, auth.AuthInfo)
+	app.GET("/authorize", a.authorize, auth.AuthInfo)
 	app.POST("/authorize", a.consent, auth.Required, auth.AuthInfo)
 
 	app.POST("/oauth/token", a.Token)
+	app.POST("/oauth/introspect", a.Introspect)
+	app.POST("/oauth/revoke", a.Revoke)
+	app.POST("/oauth/device_authorization", a.DeviceAuthorization)
+	app.POST("/oauth/par", a.PushedAuthorization)
+	app.GET("/device", a.device, auth.Required, auth.AuthInfo)
+	app.POST("/device", a.deviceConsent, auth.Required, auth.AuthInfo)
 	app.GET("/.well-known/jwks.json", a.JWKs)
 	app.GET("/userinfo", a.UserInfo)
+	app.POST("/userinfo", a.UserInfo)
 	app.GET("/oidc/logout", a.Logout)
 
 	if a.Providers.Google.Enable {
@@ -0,0 +1,97 @@
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AccessTknFormatJWT    = "jwt"
+	AccessTknFormatOpaque = "opaque"
+)
+
+// newOpaqueAccessTkn issues a random reference token for the claims. Only
+// its hash is stored, and it is bound to the session so that it stops
+// resolving as soon as the session is gone.
+func (a API) newOpaqueAccessTkn(ctx context.Context, claims AccessTknClaims) (string, error) {
+	tkn, err := util.GenerateRandom(50)
+	if err != nil {
+		return "", err
+	}
+	var act sql.NullString
+	if claims.Act != nil {
+		b, err := json.Marshal(claims.Act)
+		if err != nil {
+			return "", err
+		}
+		act = sql.NullString{String: string(b), Valid: true}
+	}
+	var jkt sql.NullString
+	if claims.Cnf != nil {
+		jkt = sql.NullString{String: claims.Cnf.Jkt, Valid: true}
+	}
+	_, err = a.DB.CreateAccessToken(ctx, sqlc.CreateAccessTokenParams{
+		ID:        hashTkn(tkn),
+		ClientID:  claims.ClientID,
+		UserID:    sql.NullString{String: claims.UserID, Valid: claims.UserID != ""},
+		SessionID: sql.NullString{String: claims.SID, Valid: claims.SID != ""},
+		Subject:   claims.Subject,
+		Audience:  claims.Audience[0],
+		Scopes:    strings.Join(claims.Scopes, " "),
+		Act:       act,
+		Jkt:       jkt,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+	return tkn, nil
+}
+
+// resolveOpaqueAccessTkn looks up a reference token and returns the claims
+// it was issued with.
+func (a API) resolveOpaqueAccessTkn(ctx context.Context, tknStr string) (*AccessTknClaims, error) {
+	at, err := a.DB.GetAccessToken(ctx, hashTkn(tknStr))
+	if err != nil {
+		return nil, err
+	}
+	if time.Until(at.ExpiresAt) <= 0 {
+		return nil, errInactiveTkn
+	}
+	var act *ActClaim
+	if at.Act.Valid {
+		act = new(ActClaim)
+		if err := json.Unmarshal([]byte(at.Act.String), act); err != nil {
+			return nil, err
+		}
+	}
+	return &AccessTknClaims{
+		UserID:   at.UserID.String,
+		ClientID: at.ClientID,
+		SID:      at.SessionID.String,
+		Scopes:   strings.Fields(at.Scopes),
+		Act:      act,
+		Cnf:      cnfClaim(at.Jkt.String),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.BaseURL,
+			Subject:   at.Subject,
+			Audience:  jwt.ClaimStrings{at.Audience},
+			IssuedAt:  jwt.NewNumericDate(at.CreatedAt),
+			ExpiresAt: jwt.NewNumericDate(at.ExpiresAt),
+		},
+	}, nil
+}
+
+// isOpaqueTkn reports whether the token is a reference token rather than
+// a JWT.
+func isOpaqueTkn(tkn string) bool {
+	return strings.Count(tkn, ".") != 2
+}
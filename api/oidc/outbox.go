@@ -0,0 +1,194 @@
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// back-channel logout delivery status
+const (
+	LogoutStatusPending   = "pending"
+	LogoutStatusDelivered = "delivered"
+	LogoutStatusFailed    = "failed"
+)
+
+const (
+	logoutOutboxPollInterval = time.Second * 10
+	logoutMaxAttempts        = 10
+	logoutInitialBackoff     = time.Second * 30
+	logoutMaxBackoff         = time.Hour
+)
+
+var errNoBackchannelLogoutURL = errors.New("app no longer supports back-channel logout")
+
+// EnqueueBackchannelLogout records a back-channel logout of the client
+// session sid of the given user in the outbox. The logout token is
+// delivered by the outbox worker, hence the caller is free to revoke the
+// session right away.
+func EnqueueBackchannelLogout(ctx context.Context, db *sqlc.Queries, clientID, userID, sid string) error {
+	id, err := util.GenerateRandom(25)
+	if err != nil {
+		return fmt.Errorf("failed to generate random string: %w", err)
+	}
+	_, err = db.CreateBackchannelLogout(ctx, sqlc.CreateBackchannelLogoutParams{
+		ID:            id,
+		ClientID:      clientID,
+		UserID:        userID,
+		SessionID:     sid,
+		NextAttemptAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert back-channel logout into db: %w", err)
+	}
+	return nil
+}
+
+// RunLogoutOutbox delivers pending back-channel logouts until ctx is
+// done. Failed deliveries are retried with exponential backoff.
+func (a API) RunLogoutOutbox(ctx context.Context) {
+	t := time.NewTicker(logoutOutboxPollInterval)
+	defer t.Stop()
+
+	for {
+		a.deliverBackchannelLogouts(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (a API) deliverBackchannelLogouts(ctx context.Context) {
+	due, err := a.DB.GetDueBackchannelLogouts(ctx, time.Now())
+	if err != nil {
+		slog.Error("failed to read due back-channel logouts", "error", err)
+		return
+	}
+
+	for _, l := range due {
+		p := sqlc.UpdateBackchannelLogoutParams{
+			ID:            l.ID,
+			Status:        LogoutStatusDelivered,
+			Attempts:      l.Attempts + 1,
+			NextAttemptAt: time.Now(),
+			DeliveredAt:   sql.NullTime{Time: time.Now(), Valid: true},
+		}
+
+		err := a.deliverBackchannelLogout(ctx, l)
+		if err != nil {
+			p.Status = LogoutStatusPending
+			p.LastError = sql.NullString{String: truncate(err.Error(), 255), Valid: true}
+			p.NextAttemptAt = time.Now().Add(logoutBackoff(p.Attempts))
+			p.DeliveredAt = sql.NullTime{}
+			if p.Attempts >= logoutMaxAttempts || errors.Is(err, errNoBackchannelLogoutURL) {
+				p.Status = LogoutStatusFailed
+			}
+			slog.Warn(
+				"back-channel logout delivery failed",
+				"id", l.ID,
+				"client_id", l.ClientID,
+				"attempts", p.Attempts,
+				"error", err,
+			)
+		}
+
+		if err := a.DB.UpdateBackchannelLogout(ctx, p); err != nil {
+			slog.Error("failed to update back-channel logout", "id", l.ID, "error", err)
+		}
+	}
+}
+
+func (a API) deliverBackchannelLogout(ctx context.Context, l sqlc.GetDueBackchannelLogoutsRow) error {
+	if !l.BackchannelLogoutUrl.Valid {
+		return errNoBackchannelLogoutURL
+	}
+
+	// the logout token is short lived, hence it is issued afresh on every
+	// attempt
+	tkn, err := a.newLogoutTkn(l.IDTokenSignedResponseAlg, l.ClientID, l.UserID, l.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to generate logout token: %w", err)
+	}
+
+	q := make(url.Values)
+	q.Set("logout_token", tkn)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*7)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		l.BackchannelLogoutUrl.String,
+		strings.NewReader(q.Encode()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call backchannel logout URI")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("client responded with non-200 status code: %s", resp.Status)
+	}
+	return nil
+}
+
+// newLogoutTkn issues a logout token (OpenID Connect Back-Channel Logout
+// section 2.4). The subject matches the one of the ID tokens issued for
+// the session.
+func (a API) newLogoutTkn(alg, clientID, userID, sid string) (string, error) {
+	jti, err := util.GenerateRandom(25)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate random string: %w", err)
+	}
+	return a.sign(alg, LogoutTknClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    a.BaseURL,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{clientID},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 2)),
+		},
+		Events: map[string]struct{}{
+			"http://schemas.openid.net/event/backchannel-logout": {},
+		},
+		SID: sid,
+	})
+}
+
+// logoutBackoff returns the delay before the next delivery attempt.
+func logoutBackoff(attempts int64) time.Duration {
+	d := logoutInitialBackoff
+	for i := int64(1); i < attempts && d < logoutMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, logoutMaxBackoff)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
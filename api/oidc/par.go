@@ -0,0 +1,171 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/labstack/echo/v4"
+)
+
+const parURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+// the request uri must outlive the login and consent round trips
+const parExpiration = time.Minute * 5
+
+// parameters carrying client credentials are never stored
+var parCredentialParams = []string{
+	"client_secret",
+	"client_assertion",
+	"client_assertion_type",
+}
+
+var errInvalidRequestURI = errors.New("invalid or expired request uri")
+
+type parResp struct {
+	Err        string `json:"error,omitempty"`
+	ErrDesc    string `json:"error_description,omitempty"`
+	RequestURI string `json:"request_uri,omitempty"`
+	ExpiresIn  int    `json:"expires_in,omitempty"`
+}
+
+// PushedAuthorization stores the authorization request parameters pushed
+// by an authenticated client and returns a request uri referencing them
+// (RFC 9126).
+func (a API) PushedAuthorization(c echo.Context) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+
+	form, err := c.FormParams()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, parResp{
+			Err:     "invalid_request",
+			ErrDesc: "failed to parse form data",
+		})
+	}
+	if form.Has("request_uri") {
+		return c.JSON(http.StatusBadRequest, parResp{
+			Err:     "invalid_request",
+			ErrDesc: "request_uri must not be pushed",
+		})
+	}
+
+	params := url.Values{}
+	if form.Has("request") {
+		obj, err := a.resolveRequestObject(c.Request().Context(), *client, form)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, parResp{
+				Err:     "invalid_request_object",
+				ErrDesc: err.Error(),
+			})
+		}
+		err = a.useJTI(c.Request().Context(), client.ID, obj.JTI, obj.ExpiresAt)
+		if err != nil {
+			if errors.Is(err, errReplayedJTI) {
+				return c.JSON(http.StatusBadRequest, parResp{
+					Err:     "invalid_request_object",
+					ErrDesc: "request object has already been used",
+				})
+			}
+			return c.JSON(http.StatusInternalServerError, parResp{
+				Err:     "server_error",
+				ErrDesc: "database operation failed",
+			})
+		}
+		params = obj.Params
+	} else {
+		if client.RequireSignedRequestObject {
+			return c.JSON(http.StatusBadRequest, parResp{
+				Err:     "invalid_request",
+				ErrDesc: "app requires a signed request object",
+			})
+		}
+		for k, v := range form {
+			params[k] = v
+		}
+		for _, k := range parCredentialParams {
+			params.Del(k)
+		}
+	}
+
+	if id := params.Get("client_id"); id != "" && id != client.ID {
+		return c.JSON(http.StatusBadRequest, parResp{
+			Err:     "invalid_request",
+			ErrDesc: "client id mismatch",
+		})
+	}
+	params.Set("client_id", client.ID)
+
+	if matchRedirectURI(*client, params.Get("redirect_uri")) == "" {
+		return c.JSON(http.StatusBadRequest, parResp{
+			Err:     "invalid_request",
+			ErrDesc: "unauthorized redirect URI",
+		})
+	}
+	if !isValidResponseMode(params.Get("response_mode")) {
+		return c.JSON(http.StatusBadRequest, parResp{
+			Err:     "invalid_request",
+			ErrDesc: "unsupported response mode",
+		})
+	}
+
+	ref, err := util.GenerateRandom(32)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, parResp{
+			Err:     "server_error",
+			ErrDesc: "failed to generate request uri",
+		})
+	}
+
+	_, err = a.DB.CreatePushedAuthzRequest(
+		c.Request().Context(),
+		sqlc.CreatePushedAuthzRequestParams{
+			ID:        hashTkn(ref),
+			ClientID:  client.ID,
+			Params:    params.Encode(),
+			ExpiresAt: time.Now().Add(parExpiration),
+		},
+	)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, parResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, parResp{
+		RequestURI: parURIPrefix + ref,
+		ExpiresIn:  int(parExpiration.Seconds()),
+	})
+}
+
+// resolvePushedRequest reads the authorization request parameters pushed
+// by the client under the given request uri.
+func (a API) resolvePushedRequest(ctx context.Context, client sqlc.Client, uri string) (*requestObject, error) {
+	ref := strings.TrimPrefix(uri, parURIPrefix)
+	par, err := a.DB.GetPushedAuthzRequest(ctx, hashTkn(ref))
+	if err != nil {
+		return nil, errInvalidRequestURI
+	}
+	if par.ClientID != client.ID || time.Now().After(par.ExpiresAt) {
+		return nil, errInvalidRequestURI
+	}
+
+	params, err := url.ParseQuery(par.Params)
+	if err != nil {
+		return nil, errInvalidRequestURI
+	}
+	return &requestObject{
+		Params:   params,
+		IssuedAt: par.CreatedAt,
+		PushedID: par.ID,
+	}, nil
+}
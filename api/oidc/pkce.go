@@ -0,0 +1,45 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"regexp"
+)
+
+const (
+	CodeChallengeMethodS256  = "S256"
+	CodeChallengeMethodPlain = "plain"
+)
+
+// code verifiers and challenges share the same alphabet and length
+// constraints (RFC 7636 section 4.1 and 4.2)
+var pkceRegexp = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+func validateCodeChallenge(challenge, method string) error {
+	if challenge == "" {
+		if method != "" {
+			return errors.New("code_challenge_method without code_challenge")
+		}
+		return nil
+	}
+	if method != CodeChallengeMethodS256 && method != CodeChallengeMethodPlain {
+		return errors.New("unsupported code challenge method")
+	}
+	if !pkceRegexp.MatchString(challenge) {
+		return errors.New("invalid code challenge")
+	}
+	return nil
+}
+
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if !pkceRegexp.MatchString(verifier) {
+		return false
+	}
+	if method == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		verifier = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(verifier)) == 1
+}
@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PromptNone    = "none"
+	PromptLogin   = "login"
+	PromptConsent = "consent"
+)
+
+// parsePrompt parses the space delimited prompt parameter. "none" can
+// not be combined with any other value.
+func parsePrompt(prompt string) (map[string]bool, error) {
+	ret := make(map[string]bool)
+	for _, p := range strings.Fields(prompt) {
+		switch p {
+		case PromptNone, PromptLogin, PromptConsent:
+			ret[p] = true
+		default:
+			return nil, errors.New("unsupported prompt value")
+		}
+	}
+	if ret[PromptNone] && len(ret) > 1 {
+		return nil, errors.New("prompt=none can not be combined with other values")
+	}
+	return ret, nil
+}
+
+// parseMaxAge parses the max_age parameter. A negative duration means
+// max_age was not requested.
+func parseMaxAge(maxAge string) (time.Duration, error) {
+	if maxAge == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(maxAge)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid max_age")
+	}
+	return time.Second * time.Duration(n), nil
+}
+
+// withoutPrompt returns the request URI without the prompt and max_age
+// parameters. The user is sent back there after logging in or consenting
+// and must not be prompted again.
+func withoutPrompt(u *url.URL) string {
+	q := u.Query()
+	q.Del("prompt")
+	q.Del("max_age")
+	ret := *u
+	ret.RawQuery = q.Encode()
+	return ret.RequestURI()
+}
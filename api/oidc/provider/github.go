@@ -36,6 +36,7 @@ const githubUserInfoURL = "https://api.github.com/user"
 
 type githubUser struct {
 	Email     string `json:"email"`
+	Name      string `json:"name"`
 	AvatarURL string `json:"avatar_url"`
 }
 
@@ -315,6 +316,7 @@ func (p ProviderGithub) Callback(c echo.Context) error {
 		ExpiresAt: expiresAt,
 		Browser:   fingerprint.Browser,
 		Os:        fingerprint.OS,
+		AuthTime:  sql.NullTime{Time: time.Now(), Valid: true},
 	})
 	if err != nil {
 		return apierr.New(
@@ -392,6 +394,7 @@ func (p ProviderGithub) InsertUser(ctx context.Context, u *githubUser) (string,
 		ID:        userID,
 		Email:     u.Email,
 		AvatarUrl: sql.NullString{String: url, Valid: true},
+		Name:      sql.NullString{String: u.Name, Valid: u.Name != ""},
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to insert new user: %w", err)
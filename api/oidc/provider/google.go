@@ -32,8 +32,10 @@ type ProviderGoogle struct {
 }
 
 type googleUser struct {
-	Email   string `json:"email"`
-	Picture string `json:"picture"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
 }
 
 func NewGoogleProvider(db *sqlc.Queries, fs fs.Storage, c Credentials) (Provider, error) {
@@ -307,6 +309,7 @@ func (p ProviderGoogle) Callback(c echo.Context) error {
 		ExpiresAt: expiresAt,
 		Browser:   fingerprint.Browser,
 		Os:        fingerprint.OS,
+		AuthTime:  sql.NullTime{Time: time.Now(), Valid: true},
 	})
 	if err != nil {
 		return apierr.New(
@@ -381,9 +384,11 @@ func (p ProviderGoogle) InsertUser(ctx context.Context, u *googleUser) (string,
 	}
 
 	_, err = p.db.CreateUser(ctx, sqlc.CreateUserParams{
-		ID:        userID,
-		Email:     u.Email,
-		AvatarUrl: sql.NullString{String: url, Valid: true},
+		ID:            userID,
+		Email:         u.Email,
+		AvatarUrl:     sql.NullString{String: url, Valid: true},
+		EmailVerified: u.EmailVerified,
+		Name:          sql.NullString{String: u.Name, Valid: u.Name != ""},
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to insert new user: %w", err)
@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// algorithms accepted for request objects. Client secrets are only stored
+// as argon2id hashes, hence request objects signed using a shared secret
+// (HS256 and friends) can not be verified and are rejected. Request
+// objects must be signed using one of the keys registered by the client.
+var requestObjectAlgs = clientAssertionAlgs
+
+// registered claims that are not authorization request parameters
+var requestObjectReservedClaims = map[string]bool{
+	"iss":         true,
+	"aud":         true,
+	"exp":         true,
+	"iat":         true,
+	"nbf":         true,
+	"jti":         true,
+	"sub":         true,
+	"request":     true,
+	"request_uri": true,
+}
+
+// requestObject holds the authorization request parameters carried by a
+// verified request object (RFC 9101) or pushed authorization request.
+type requestObject struct {
+	Params   url.Values
+	IssuedAt time.Time
+	// jti and expiry of a request object passed by value. The jti is
+	// recorded once the request is acted upon, which makes the request
+	// object single use.
+	JTI       string
+	ExpiresAt time.Time
+	// set when the parameters were pushed by the client (RFC 9126)
+	PushedID string
+}
+
+// resolveRequestObject verifies the request object passed either by value
+// (request) or by reference (request_uri) in q. As per RFC 9101 section 6.3,
+// only the parameters inside the request object are to be used. Request
+// objects are never fetched from the network; the only request uris
+// accepted are the ones issued by the pushed authorization request
+// endpoint, which are resolved from the database.
+func (a API) resolveRequestObject(ctx context.Context, client sqlc.Client, q url.Values) (*requestObject, error) {
+	raw := q.Get("request")
+	if uri := q.Get("request_uri"); uri != "" {
+		if raw != "" {
+			return nil, errors.New("request and request_uri must not be used together")
+		}
+		if !strings.HasPrefix(uri, parURIPrefix) {
+			return nil, errors.New("request uri must be issued by the pushed authorization request endpoint")
+		}
+		return a.resolvePushedRequest(ctx, client, uri)
+	}
+
+	keys, err := a.clientJWKs(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(
+		raw, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			return keysFor(keys, t), nil
+		},
+		jwt.WithValidMethods(requestObjectAlgs),
+		jwt.WithIssuer(client.ID),
+		jwt.WithAudience(a.BaseURL),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, errors.New("invalid request object")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, errors.New("request object must include jti")
+	}
+
+	if id, ok := claims["client_id"]; ok && id != client.ID {
+		return nil, errors.New("request object client id mismatch")
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil {
+		return nil, errors.New("invalid request object")
+	}
+	obj := &requestObject{
+		Params:    url.Values{},
+		JTI:       jti,
+		ExpiresAt: exp.Time,
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		obj.IssuedAt = iat.Time
+	}
+
+	for k, v := range claims {
+		if requestObjectReservedClaims[k] {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			obj.Params.Set(k, v)
+		case float64:
+			obj.Params.Set(k, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	obj.Params.Set("client_id", client.ID)
+
+	// prompt=login and prompt=consent are satisfied by an authentication
+	// or a consent that happened after the request object was issued.
+	// Without iat, the user would be asked over and over again.
+	if obj.IssuedAt.IsZero() {
+		prompt := obj.Params.Get("prompt")
+		if strings.Contains(prompt, PromptLogin) || strings.Contains(prompt, PromptConsent) {
+			return nil, errors.New("request object with prompt=login or prompt=consent must include iat")
+		}
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,56 @@
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/internal/sqlc"
+)
+
+var errInvalidTarget = errors.New("unknown or unsupported resource")
+
+// resourceParam returns the resource indicator of a request (RFC 8707).
+// The audience parameter is accepted as an alias for clients that send
+// it instead.
+func resourceParam(resource, audience string) string {
+	if resource != "" {
+		return resource
+	}
+	return audience
+}
+
+// resolveResource looks up the API registered under the given identifier.
+// A nil API is returned when no resource was requested, in which case
+// access tokens are issued for the client itself.
+func (a API) resolveResource(ctx context.Context, identifier string) (*sqlc.ApiResource, error) {
+	if identifier == "" {
+		return nil, nil
+	}
+	api, err := a.DB.GetAPIResourceByIdentifier(ctx, identifier)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInvalidTarget
+		}
+		return nil, err
+	}
+	return &api, nil
+}
+
+// apiScopes returns the scopes defined by the API, if any.
+func apiScopes(api *sqlc.ApiResource) []string {
+	if api == nil {
+		return nil
+	}
+	return strings.Fields(api.Scopes.String)
+}
+
+// accessTknLifetime returns how long access tokens for the API are valid.
+func accessTknLifetime(api *sqlc.ApiResource) time.Duration {
+	if api == nil {
+		return accessTknExpiration
+	}
+	return time.Second * time.Duration(api.TokenExpiration)
+}
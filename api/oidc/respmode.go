@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/murtaza-u/ellipsis/api/render"
+	"github.com/murtaza-u/ellipsis/view"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ResponseModeQuery    = "query"
+	ResponseModeFragment = "fragment"
+	ResponseModeFormPost = "form_post"
+)
+
+func isValidResponseMode(mode string) bool {
+	switch mode {
+	case "", ResponseModeQuery, ResponseModeFragment, ResponseModeFormPost:
+		return true
+	}
+	return false
+}
+
+// authzRedirect delivers an authorization response (success or error) to
+// the client's redirect URI using the requested response mode.
+type authzRedirect struct {
+	URI   string
+	Mode  string
+	State string
+}
+
+// URL returns the redirect URI with params encoded in the query or in the
+// fragment. It must not be used with the form_post response mode.
+func (r authzRedirect) URL(params url.Values) string {
+	params = r.withState(params)
+	if r.Mode == ResponseModeFragment {
+		return withFragment(r.URI, params)
+	}
+	return withQuery(r.URI, params)
+}
+
+// Send writes the authorization response to c.
+func (r authzRedirect) Send(c echo.Context, params url.Values) error {
+	if r.Mode == ResponseModeFormPost {
+		return render.Do(render.Params{
+			Ctx:       c,
+			Component: view.FormPost(r.URI, r.withState(params)),
+		})
+	}
+	return c.Redirect(http.StatusFound, r.URL(params))
+}
+
+func (r authzRedirect) withState(params url.Values) url.Values {
+	v := url.Values{}
+	for k, vs := range params {
+		v[k] = vs
+	}
+	if r.State != "" {
+		v.Set("state", r.State)
+	}
+	return v
+}
+
+// withQuery merges params into the query component of base, preserving
+// any query parameters already registered with the redirect URI.
+func withQuery(base string, params url.Values) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?" + params.Encode()
+	}
+	q := u.Query()
+	for k, vs := range params {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// withFragment replaces the fragment component of base with params.
+func withFragment(base string, params url.Values) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "#" + params.Encode()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String() + "#" + params.Encode()
+}
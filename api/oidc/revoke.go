@@ -0,0 +1,112 @@
+package oidc
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	TknTypeHintAccessTkn  = "access_token"
+	TknTypeHintRefreshTkn = "refresh_token"
+)
+
+type revokeParams struct {
+	Tkn         string `form:"token"`
+	TknTypeHint string `form:"token_type_hint"`
+}
+
+// Revoke revokes an access or refresh token as per RFC 7009. Both kinds
+// of token are bound to a session, so revoking either ends the session
+// and with it every other token issued alongside. Opaque access tokens
+// issued without a session or through token exchange are deleted on their
+// own, since the session belongs to the client of the subject token.
+func (a API) Revoke(c echo.Context) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+
+	params := new(revokeParams)
+	if err := c.Bind(params); err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "failed to parse form data",
+		})
+	}
+	if params.Tkn == "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "missing token",
+		})
+	}
+
+	ctx := c.Request().Context()
+
+	// the hint only decides which lookup is tried first
+	var sessionID, clientID string
+	if params.TknTypeHint != TknTypeHintAccessTkn {
+		rt, err := a.DB.GetRefreshTokenWithSession(ctx, hashTkn(params.Tkn))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusInternalServerError, tknResp{
+				Err:     "server_error",
+				ErrDesc: "database operation failed",
+			})
+		}
+		if err == nil {
+			sessionID, clientID = rt.SessionID, rt.ClientID.String
+		}
+	}
+	if sessionID == "" {
+		claims, err := a.resolveAccessTkn(ctx, params.Tkn)
+		if err != nil {
+			// invalid tokens do not cause an error response (RFC 7009
+			// section 2.2)
+			return c.NoContent(http.StatusOK)
+		}
+		// opaque tokens without a session of their own are revoked on
+		// their own
+		ownSession := claims.SID != "" && claims.Act == nil
+		if !ownSession && isOpaqueTkn(params.Tkn) {
+			if claims.ClientID != client.ID {
+				return c.JSON(http.StatusBadRequest, tknResp{
+					Err:     "unauthorized_client",
+					ErrDesc: "token was issued to another client",
+				})
+			}
+			err := a.DB.DeleteAccessToken(ctx, hashTkn(params.Tkn))
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, tknResp{
+					Err:     "server_error",
+					ErrDesc: "database operation failed",
+				})
+			}
+			return c.NoContent(http.StatusOK)
+		}
+		if !ownSession {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "unsupported_token_type",
+				ErrDesc: "self-contained access tokens without a session can not be revoked",
+			})
+		}
+		sessionID, clientID = claims.SID, claims.ClientID
+	}
+
+	if clientID != client.ID {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "unauthorized_client",
+			ErrDesc: "token was issued to another client",
+		})
+	}
+
+	err = a.DB.DeleteSession(ctx, sessionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -1,35 +1,68 @@
 package oidc
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/conf"
 	"github.com/murtaza-u/ellipsis/internal/sqlc"
 
-	"github.com/alexedwards/argon2id"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	GrantTypeAuthzCode         = "authorization_code"
+	GrantTypeRefreshTkn        = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+const (
+	accessTknExpiration  = time.Second * 1800
+	refreshTknExpiration = time.Hour * 24 * 30
+	// refreshing never extends a session past this long after it was
+	// created
+	maxSessionLifetime = time.Hour * 24 * 90
+)
+
 type tknParams struct {
-	ClientID     string `form:"client_id"`
-	ClientSecret string `form:"client_secret"`
 	Code         string `form:"code"`
+	CodeVerifier string `form:"code_verifier"`
+	DeviceCode   string `form:"device_code"`
 	GrantType    string `form:"grant_type"`
+	RefreshTkn   string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	Resource     string `form:"resource"`
+	Audience     string `form:"audience"`
+	// token exchange (RFC 8693)
+	SubjectTkn       string `form:"subject_token"`
+	SubjectTknType   string `form:"subject_token_type"`
+	ActorTkn         string `form:"actor_token"`
+	RequestedTknType string `form:"requested_token_type"`
+	// thumbprint of the DPoP proof key, if the request carried a proof
+	jkt string
 }
 
 type tknResp struct {
-	Err       string `json:"error,omitempty"`
-	ErrDesc   string `json:"error_description,omitempty"`
-	AccessTkn string `json:"access_token,omitempty"`
-	TknType   string `json:"token_type,omitempty"`
-	ExpiresIn int    `json:"expires_in,omitempty"`
-	Scope     string `json:"scope,omitempty"`
-	IDTkn     string `json:"id_token,omitempty"`
+	Err        string `json:"error,omitempty"`
+	ErrDesc    string `json:"error_description,omitempty"`
+	AccessTkn  string `json:"access_token,omitempty"`
+	TknType    string `json:"token_type,omitempty"`
+	ExpiresIn  int    `json:"expires_in,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+	IDTkn      string `json:"id_token,omitempty"`
+	RefreshTkn string `json:"refresh_token,omitempty"`
+	// only set in response to a token exchange
+	IssuedTknType string `json:"issued_token_type,omitempty"`
 }
 
 func (a API) Token(c echo.Context) error {
@@ -40,12 +73,49 @@ func (a API) Token(c echo.Context) error {
 			ErrDesc: "failed to parse form data",
 		})
 	}
-	if params.GrantType != "authorization_code" {
+
+	jkt, err := a.dpopProof(c, "")
+	if err != nil {
+		if errors.Is(err, errInvalidDPoPProof) {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "invalid_dpop_proof",
+				ErrDesc: err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	params.jkt = jkt
+
+	switch params.GrantType {
+	case GrantTypeAuthzCode:
+		return a.authzCodeGrant(c, params)
+	case GrantTypeRefreshTkn:
+		return a.refreshTknGrant(c, params)
+	case GrantTypeClientCredentials:
+		return a.clientCredentialsGrant(c, params)
+	case GrantTypeDeviceCode:
+		return a.deviceCodeGrant(c, params)
+	case GrantTypeTknExchange:
+		return a.tknExchangeGrant(c, params)
+	default:
 		return c.JSON(http.StatusBadRequest, tknResp{
 			Err:     "bad_request",
 			ErrDesc: "invalid or unsupported grant_type",
 		})
 	}
+}
+
+func (a API) authzCodeGrant(c echo.Context, params *tknParams) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+	if client.RequireDpop && params.jkt == "" {
+		return missingDPoPProof(c)
+	}
 
 	metadata, err := a.DB.GetAuthzCode(c.Request().Context(), params.Code)
 	if err != nil {
@@ -61,40 +131,207 @@ func (a API) Token(c echo.Context) error {
 		})
 	}
 
-	if metadata.ClientID != params.ClientID {
+	if metadata.ClientID != client.ID {
 		return c.JSON(http.StatusBadRequest, tknResp{
-			Err:     "unauthorized",
-			ErrDesc: "invalid client id or secret",
+			Err:     "invalid_grant",
+			ErrDesc: "authorization code was issued to another client",
 		})
 	}
-	client, err := a.DB.GetClient(c.Request().Context(), metadata.ClientID)
-	if err != nil {
+	if metadata.ExpiresAt.Valid && time.Now().After(metadata.ExpiresAt.Time) {
 		return c.JSON(http.StatusBadRequest, tknResp{
-			Err:     "unauthorized",
-			ErrDesc: "invalid client id or secret",
+			Err:     "invalid_grant",
+			ErrDesc: "authorization code expired",
 		})
 	}
-	match, err := argon2id.ComparePasswordAndHash(params.ClientSecret, client.SecretHash)
-	if err != nil || !match {
+
+	if metadata.CodeChallenge.Valid {
+		if params.CodeVerifier == "" {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "invalid_grant",
+				ErrDesc: "missing code verifier",
+			})
+		}
+		if !verifyCodeChallenge(
+			metadata.CodeChallenge.String,
+			metadata.CodeChallengeMethod.String,
+			params.CodeVerifier,
+		) {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "invalid_grant",
+				ErrDesc: "code verifier does not match code challenge",
+			})
+		}
+	} else if client.ClientType == ClientTypePublic {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "public clients must use PKCE",
+		})
+	} else if params.CodeVerifier != "" {
 		return c.JSON(http.StatusBadRequest, tknResp{
-			Err:     "unauthorized",
-			ErrDesc: "invalid client id or secret",
+			Err:     "invalid_grant",
+			ErrDesc: "code verifier supplied without code challenge",
 		})
 	}
 
-	accessTkn := jwt.NewWithClaims(jwt.SigningMethodEdDSA, AccessTknClaims{
-		UserID: metadata.UserID,
-		Scopes: strings.Split(metadata.Scopes, " "),
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    a.BaseURL,
-			Subject:   a.BaseURL + "/userinfo",
-			Audience:  jwt.ClaimStrings{metadata.ClientID},
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * 1800)),
-		},
+	// the token request may repeat, but not change, the resource the
+	// code was issued for
+	resource := resourceParam(params.Resource, params.Audience)
+	if resource != "" && resource != metadata.Resource.String {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: "resource does not match the authorized resource",
+		})
+	}
+
+	// invalidate auth code. Only the request that removed it gets tokens.
+	res, err := a.DB.DeleteAuthzCode(c.Request().Context(), params.Code)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, tknResp{
+			Err:     "server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "authorization code has already been used",
+		})
+	}
+
+	return a.issueSessionTkns(c, client, grant{
+		UserID:   metadata.UserID,
+		Scope:    metadata.Scopes,
+		Nonce:    metadata.Nonce.String,
+		AuthTime: metadata.AuthTime,
+		Os:       metadata.Os,
+		Browser:  metadata.Browser,
+		ParentID: metadata.SessionID,
+		Resource: metadata.Resource,
+		Jkt:      params.jkt,
 	})
-	accessTknStr, err := accessTkn.SignedString(a.Key.Priv)
+}
+
+func (a API) refreshTknGrant(c echo.Context, params *tknParams) error {
+	if params.RefreshTkn == "" {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_request",
+			ErrDesc: "missing refresh token",
+		})
+	}
+
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+	if client.RequireDpop && params.jkt == "" {
+		return missingDPoPProof(c)
+	}
+
+	rt, err := a.DB.GetRefreshTokenWithSession(
+		c.Request().Context(),
+		hashTkn(params.RefreshTkn),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "invalid_grant",
+				ErrDesc: "invalid or revoked refresh token",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	if rt.ClientID.String != client.ID {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "refresh token was issued to another client",
+		})
+	}
+
+	// a refresh token is good for exactly one use. Replaying an already
+	// rotated token means it has leaked, so revoke the whole family by
+	// revoking the session it belongs to.
+	if rt.Used {
+		a.DB.DeleteSession(c.Request().Context(), rt.SessionID)
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "refresh token reuse detected",
+		})
+	}
+
+	if time.Until(rt.ExpiresAt) <= 0 {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "refresh token expired",
+		})
+	}
+	sessionLimit := rt.CreatedAt.Add(maxSessionLifetime)
+	if time.Until(sessionLimit) <= 0 {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "session has reached its maximum lifetime",
+		})
+	}
+
+	// refresh tokens issued with a DPoP proof can only be used with a
+	// proof signed by the same key (RFC 9449 section 5)
+	if rt.Jkt.Valid && rt.Jkt.String != params.jkt {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "refresh token is bound to another DPoP key",
+		})
+	}
+
+	resource := resourceParam(params.Resource, params.Audience)
+	if resource != "" && resource != rt.Resource.String {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: "resource does not match the authorized resource",
+		})
+	}
+	api, err := a.resolveResource(c.Request().Context(), rt.Resource.String)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: err.Error(),
+		})
+	}
+
+	scopes := strings.Fields(rt.Scopes)
+	if params.Scope != "" {
+		requested := strings.Fields(params.Scope)
+		for _, s := range requested {
+			if !hasScope(scopes, s) {
+				return c.JSON(http.StatusBadRequest, tknResp{
+					Err:     "invalid_scope",
+					ErrDesc: "requested scope exceeds the originally granted scope",
+				})
+			}
+		}
+		scopes = requested
+	}
+
+	res, err := a.DB.MarkRefreshTokenUsed(c.Request().Context(), rt.ID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_server_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+	// lost the race against a concurrent request using the same token
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		a.DB.DeleteSession(c.Request().Context(), rt.SessionID)
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_grant",
+			ErrDesc: "refresh token reuse detected",
+		})
+	}
+
+	accessTknStr, err := a.newAccessTkn(c.Request().Context(),
+		rt.UserID, client, api, rt.SessionID, scopes, params.jkt)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, tknResp{
 			Err:     "internal_error",
@@ -102,41 +339,194 @@ func (a API) Token(c echo.Context) error {
 		})
 	}
 
-	sessionID, err := util.GenerateRandom(25)
+	var idTknStr string
+	if hasScope(scopes, ScopeOIDC) {
+		// refreshed id tokens carry no nonce (OIDC core section 12.2)
+		idTknStr, err = a.newIDTkn(c.Request().Context(), idTknParams{
+			SID:       rt.SessionID,
+			Client:    client,
+			UserID:    rt.UserID,
+			Scopes:    scopes,
+			AuthTime:  rt.AuthTime,
+			AccessTkn: accessTknStr,
+		})
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "internal_error",
+				ErrDesc: "failed to generate id token",
+			})
+		}
+	}
+
+	// the rotated token keeps the originally granted scope and stays
+	// bound to its key
+	jkt := rt.Jkt.String
+	if !rt.Jkt.Valid {
+		jkt = params.jkt
+	}
+	refreshTknStr, refreshTknExp, err := a.newRefreshTkn(
+		c.Request().Context(),
+		rt.SessionID,
+		rt.Scopes,
+		rt.Resource,
+		jkt,
+		sessionLimit,
+	)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, tknResp{
 			Err:     "internal_error",
-			ErrDesc: "failed to generate auth session id",
+			ErrDesc: "failed to generate refresh token",
 		})
 	}
 
-	idTknExp := time.Now().Add(time.Second * time.Duration(client.TokenExpiration))
-	idTkn := jwt.NewWithClaims(jwt.SigningMethodEdDSA, IDTknClaims{
-		SID: sessionID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    a.BaseURL,
-			Subject:   metadata.ClientID,
-			Audience:  jwt.ClaimStrings{metadata.ClientID},
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(idTknExp),
+	err = a.DB.UpdateSessionExpiry(
+		c.Request().Context(),
+		sqlc.UpdateSessionExpiryParams{
+			ID:        rt.SessionID,
+			ExpiresAt: refreshTknExp,
 		},
+	)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_error",
+			ErrDesc: "database operation failed",
+		})
+	}
+
+	return c.JSON(http.StatusOK, tknResp{
+		AccessTkn:  accessTknStr,
+		TknType:    tknType(params.jkt),
+		ExpiresIn:  int(accessTknLifetime(api).Seconds()),
+		Scope:      strings.Join(scopes, " "),
+		IDTkn:      idTknStr,
+		RefreshTkn: refreshTknStr,
 	})
-	idTknStr, err := idTkn.SignedString(a.Key.Priv)
+}
+
+func (a API) clientCredentialsGrant(c echo.Context, params *tknParams) error {
+	client, err := a.authenticateClient(c)
+	if err != nil {
+		return invalidClient(c, err)
+	}
+	if client.ClientType == ClientTypePublic {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "unauthorized_client",
+			ErrDesc: "public clients can not use the client credentials grant",
+		})
+	}
+	if client.RequireDpop && params.jkt == "" {
+		return missingDPoPProof(c)
+	}
+
+	api, err := a.resolveResource(
+		c.Request().Context(),
+		resourceParam(params.Resource, params.Audience),
+	)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: err.Error(),
+		})
+	}
+
+	// defaults to every scope allowed for the client, narrowed down to
+	// the scopes of the requested API
+	allowed := strings.Fields(client.AllowedScopes.String)
+	if api != nil {
+		var inAPI []string
+		for _, s := range allowed {
+			if hasScope(apiScopes(api), s) {
+				inAPI = append(inAPI, s)
+			}
+		}
+		allowed = inAPI
+	}
+	scopes := allowed
+	if params.Scope != "" {
+		scopes = strings.Fields(params.Scope)
+		for _, s := range scopes {
+			if !hasScope(allowed, s) {
+				return c.JSON(http.StatusBadRequest, tknResp{
+					Err:     "invalid_scope",
+					ErrDesc: "scope not allowed for client",
+				})
+			}
+		}
+	}
+
+	accessTknStr, err := a.newAccessTkn(c.Request().Context(),
+		"", client, api, "", scopes, params.jkt)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, tknResp{
 			Err:     "internal_error",
-			ErrDesc: "failed to generate id token",
+			ErrDesc: "failed to generate access token",
 		})
 	}
 
+	return c.JSON(http.StatusOK, tknResp{
+		AccessTkn: accessTknStr,
+		TknType:   tknType(params.jkt),
+		ExpiresIn: int(accessTknLifetime(api).Seconds()),
+		Scope:     strings.Join(scopes, " "),
+	})
+}
+
+// grant is an authorization granted by a user, either through the
+// authorization code or the device flow.
+type grant struct {
+	UserID   string
+	Scope    string
+	Nonce    string
+	AuthTime sql.NullTime
+	Os       sql.NullString
+	Browser  sql.NullString
+	// browser session the grant was made from, if any
+	ParentID sql.NullString
+	// identifier of the API the access token is issued for, if any
+	Resource sql.NullString
+	// thumbprint of the DPoP key the tokens are bound to, if any
+	Jkt string
+}
+
+// issueSessionTkns starts a new session for the grant and responds with
+// the tokens bound to it.
+func (a API) issueSessionTkns(c echo.Context, client *sqlc.Client, g grant) error {
+	scopes := strings.Fields(g.Scope)
+
+	api, err := a.resolveResource(c.Request().Context(), g.Resource.String)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "invalid_target",
+			ErrDesc: err.Error(),
+		})
+	}
+
+	sessionID, err := util.GenerateRandom(25)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_error",
+			ErrDesc: "failed to generate auth session id",
+		})
+	}
+
+	// offline sessions outlive the id token and last as long as their
+	// refresh token
+	isOffline := hasScope(scopes, ScopeOfflineAccess)
+	sessionExp := time.Now().Add(time.Second * time.Duration(client.TokenExpiration))
+	if isOffline {
+		sessionExp = time.Now().Add(refreshTknExpiration)
+	}
+
+	// the session is created first as opaque access tokens reference it
 	_, err = a.DB.CreateSession(c.Request().Context(), sqlc.CreateSessionParams{
 		ID:        sessionID,
-		UserID:    metadata.UserID,
-		ClientID:  sql.NullString{String: metadata.ClientID, Valid: true},
-		ExpiresAt: idTknExp,
-		Os:        metadata.Os,
-		Browser:   metadata.Browser,
+		UserID:    g.UserID,
+		ClientID:  sql.NullString{String: client.ID, Valid: true},
+		ExpiresAt: sessionExp,
+		Os:        g.Os,
+		Browser:   g.Browser,
+		AuthTime:  g.AuthTime,
+		ParentID:  g.ParentID,
 	})
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, tknResp{
@@ -145,14 +535,208 @@ func (a API) Token(c echo.Context) error {
 		})
 	}
 
-	// invalidate auth code
-	a.DB.DeleteAuthzCode(c.Request().Context(), params.Code)
+	accessTknStr, err := a.newAccessTkn(c.Request().Context(),
+		g.UserID, client, api, sessionID, scopes, g.Jkt)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_error",
+			ErrDesc: "failed to generate access token",
+		})
+	}
 
-	return c.JSON(http.StatusOK, tknResp{
+	idTknStr, err := a.newIDTkn(c.Request().Context(), idTknParams{
+		SID:       sessionID,
+		Client:    client,
+		UserID:    g.UserID,
+		Scopes:    scopes,
+		Nonce:     g.Nonce,
+		AuthTime:  g.AuthTime,
 		AccessTkn: accessTknStr,
-		TknType:   "Bearer",
-		ExpiresIn: 1800,
-		Scope:     metadata.Scopes,
-		IDTkn:     idTknStr,
 	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, tknResp{
+			Err:     "internal_error",
+			ErrDesc: "failed to generate id token",
+		})
+	}
+
+	var refreshTknStr string
+	if isOffline {
+		refreshTknStr, _, err = a.newRefreshTkn(
+			c.Request().Context(),
+			sessionID,
+			g.Scope,
+			g.Resource,
+			g.Jkt,
+			time.Now().Add(maxSessionLifetime),
+		)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, tknResp{
+				Err:     "internal_error",
+				ErrDesc: "failed to generate refresh token",
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, tknResp{
+		AccessTkn:  accessTknStr,
+		TknType:    tknType(g.Jkt),
+		ExpiresIn:  int(accessTknLifetime(api).Seconds()),
+		Scope:      g.Scope,
+		IDTkn:      idTknStr,
+		RefreshTkn: refreshTknStr,
+	})
+}
+
+// newAccessTkn issues an access token on behalf of the given user. The
+// token is bound to the DPoP key with the given thumbprint, if any.
+func (a API) newAccessTkn(ctx context.Context, userID string, client *sqlc.Client, api *sqlc.ApiResource, sid string, scopes []string, jkt string) (string, error) {
+	claims := a.newAccessTknClaims(userID, client, api, sid, scopes)
+	claims.Cnf = cnfClaim(jkt)
+	return a.issueAccessTkn(ctx, client, claims)
+}
+
+// newAccessTknClaims returns the claims of an access token. Tokens issued
+// without a user (client credentials grant) have the client as their
+// subject and are not bound to a session. Tokens issued for an API have
+// it as their audience and the user as their subject.
+func (a API) newAccessTknClaims(userID string, client *sqlc.Client, api *sqlc.ApiResource, sid string, scopes []string) AccessTknClaims {
+	sub := a.BaseURL + "/userinfo"
+	aud := client.ID
+	if api != nil {
+		sub = userID
+		aud = api.Identifier
+	}
+	if userID == "" {
+		sub = client.ID
+	}
+	return AccessTknClaims{
+		UserID:   userID,
+		ClientID: client.ID,
+		SID:      sid,
+		Scopes:   scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.BaseURL,
+			Subject:   sub,
+			Audience:  jwt.ClaimStrings{aud},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTknLifetime(api))),
+		},
+	}
+}
+
+// issueAccessTkn signs the claims, or stores them behind a reference token
+// for clients using opaque access tokens.
+func (a API) issueAccessTkn(ctx context.Context, client *sqlc.Client, claims AccessTknClaims) (string, error) {
+	if client.AccessTokenFormat == AccessTknFormatOpaque {
+		return a.newOpaqueAccessTkn(ctx, claims)
+	}
+	return a.sign(client.IDTokenSignedResponseAlg, claims)
+}
+
+type idTknParams struct {
+	SID       string
+	Client    *sqlc.Client
+	UserID    string
+	Scopes    []string
+	Nonce     string
+	AuthTime  sql.NullTime
+	AccessTkn string
+}
+
+// newIDTkn issues an id token identifying the user. Claims describing the
+// user are only included if the corresponding scope was granted.
+func (a API) newIDTkn(ctx context.Context, p idTknParams) (string, error) {
+	claims := IDTknClaims{
+		SID:    p.SID,
+		Nonce:  p.Nonce,
+		AtHash: atHash(p.Client.IDTokenSignedResponseAlg, p.AccessTkn),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.BaseURL,
+			Subject:   p.UserID,
+			Audience:  jwt.ClaimStrings{p.Client.ID},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(
+				time.Second * time.Duration(p.Client.TokenExpiration))),
+		},
+	}
+	if p.AuthTime.Valid {
+		claims.AuthTime = jwt.NewNumericDate(p.AuthTime.Time)
+	}
+	if hasUserClaimScope(p.Scopes) {
+		u, err := a.DB.GetUser(ctx, p.UserID)
+		if err != nil {
+			return "", err
+		}
+		claims.UserClaims = newUserClaims(u, p.Scopes)
+	}
+	return a.sign(p.Client.IDTokenSignedResponseAlg, claims)
+}
+
+// atHash computes the at_hash claim: the left-most half of the access
+// token hash, using the hash function of the id token signing algorithm.
+// EdDSA with Ed25519 uses SHA-512.
+func atHash(alg, accessTkn string) string {
+	if accessTkn == "" {
+		return ""
+	}
+	if alg == conf.AlgEdDSA {
+		sum := sha512.Sum512([]byte(accessTkn))
+		return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+	}
+	sum := sha256.Sum256([]byte(accessTkn))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// newRefreshTkn generates a refresh token bound to the given session and,
+// if a thumbprint is given, to a DPoP key. Only the hash of the token is
+// persisted. The token never expires after notAfter.
+func (a API) newRefreshTkn(ctx context.Context, sessionID, scopes string, resource sql.NullString, jkt string, notAfter time.Time) (string, time.Time, error) {
+	tkn, err := util.GenerateRandom(50)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	exp := time.Now().Add(refreshTknExpiration)
+	if exp.After(notAfter) {
+		exp = notAfter
+	}
+	_, err = a.DB.CreateRefreshToken(ctx, sqlc.CreateRefreshTokenParams{
+		ID:        hashTkn(tkn),
+		SessionID: sessionID,
+		Scopes:    scopes,
+		Resource:  resource,
+		Jkt:       sql.NullString{String: jkt, Valid: jkt != ""},
+		ExpiresAt: exp,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tkn, exp, nil
+}
+
+func hashTkn(tkn string) string {
+	sum := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(sum[:])
+}
+
+// hasUserClaimScope reports whether any of the scopes releases claims
+// describing the user.
+func hasUserClaimScope(scopes []string) bool {
+	for _, s := range []string{ScopeProfile, ScopeEmail, ScopePhone, ScopeAddress} {
+		if hasScope(scopes, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
@@ -12,77 +12,101 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	schemeBearer = "Bearer"
+	schemeDPoP   = "DPoP"
+)
+
 type UserInfo struct {
-	Err       string `json:"error,omitempty"`
-	ErrDesc   string `json:"error_description,omitempty"`
-	Email     string `json:"email,omitempty"`
-	AvatarURL string `json:"avatar_url,omitempty"`
+	Err     string `json:"error,omitempty"`
+	ErrDesc string `json:"error_description,omitempty"`
+	Sub     string `json:"sub,omitempty"`
+	UserClaims
+}
+
+// userInfoClaims are the claims of a signed userinfo response.
+type userInfoClaims struct {
+	UserClaims
+	jwt.RegisteredClaims
 }
 
+// UserInfo returns the claims of the user the access token was issued to.
+// The token may be presented in the Authorization header or, for POST
+// requests, as the form-encoded access_token parameter (RFC 6750 section
+// 2). DPoP-bound tokens must be presented using the DPoP scheme along
+// with a proof signed by the key they are bound to (RFC 9449 section 7).
+// Clients with a userinfo signing algorithm configured receive the claims
+// as a signed JWT.
 func (a API) UserInfo(c echo.Context) error {
-	header := c.Request().Header.Get("Authorization")
-	if header == "" {
-		return c.JSON(http.StatusBadRequest, UserInfo{
-			Err:     "bad_request",
-			ErrDesc: "missing Authorization header in request",
-		})
-	}
-	tknStr, err := tknFromHeader(header)
+	tknStr, scheme, err := userInfoTkn(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, UserInfo{
-			Err:     "bad_request",
-			ErrDesc: err.Error(),
-		})
+		return userInfoErr(c, http.StatusBadRequest, "invalid_request", err.Error())
+	}
+	if tknStr == "" {
+		return userInfoErr(c, http.StatusUnauthorized, "", "")
 	}
 
-	claims := new(AccessTknClaims)
-	_, err = jwt.ParseWithClaims(
-		tknStr, claims,
-		func(t *jwt.Token) (interface{}, error) {
-			return a.Key.Pub, nil
-		},
-	)
+	ctx := c.Request().Context()
+	claims, err := a.resolveAccessTkn(ctx, tknStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, UserInfo{
-			Err:     "bad_request",
-			ErrDesc: "invalid or expired access token",
-		})
+		return userInfoErr(c, http.StatusUnauthorized, "invalid_token", err.Error())
 	}
 
-	exp, err := claims.GetExpirationTime()
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, UserInfo{
-			Err:     "bad_request",
-			ErrDesc: "invalid or expired access token",
-		})
+	switch {
+	case claims.Cnf != nil && scheme != schemeDPoP:
+		return userInfoErr(c, http.StatusUnauthorized, "invalid_token",
+			"DPoP-bound access token presented as a bearer token")
+	case claims.Cnf == nil && scheme == schemeDPoP:
+		return userInfoErr(c, http.StatusUnauthorized, "invalid_token",
+			"access token is not DPoP-bound")
+	case claims.Cnf != nil:
+		jkt, err := a.dpopProof(c, tknStr)
+		if err != nil {
+			if errors.Is(err, errInvalidDPoPProof) {
+				return userInfoErr(c, http.StatusUnauthorized, "invalid_dpop_proof", err.Error())
+			}
+			return c.JSON(http.StatusInternalServerError, UserInfo{
+				Err:     "internal",
+				ErrDesc: "database operation failed",
+			})
+		}
+		if jkt == "" {
+			return userInfoErr(c, http.StatusUnauthorized, "invalid_dpop_proof",
+				"missing DPoP proof")
+		}
+		if jkt != claims.Cnf.Jkt {
+			return userInfoErr(c, http.StatusUnauthorized, "invalid_dpop_proof",
+				"DPoP proof is signed by another key than the token is bound to")
+		}
 	}
-	if time.Until(exp.Time) <= 0 {
-		return c.JSON(http.StatusBadRequest, UserInfo{
-			Err:     "bad_request",
-			ErrDesc: "invalid or expired access token",
-		})
+
+	if claims.UserID == "" {
+		return userInfoErr(c, http.StatusUnauthorized, "invalid_token",
+			"access token is not bound to a user")
 	}
 
-	var isAuthz bool
-	for _, s := range claims.Scopes {
-		if s == ScopeProfile {
-			isAuthz = true
-		}
+	if !hasScope(claims.Scopes, ScopeOIDC) {
+		return userInfoErr(c, http.StatusForbidden, "insufficient_scope",
+			"access token does not contain the openid scope")
 	}
-	if !isAuthz {
-		return c.JSON(http.StatusBadRequest, UserInfo{
-			Err:     "unauthorized",
-			ErrDesc: "access token does not contain the required scope",
+
+	u, err := a.DB.GetUser(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userInfoErr(c, http.StatusUnauthorized, "invalid_token",
+				"invalid user id")
+		}
+		return c.JSON(http.StatusInternalServerError, UserInfo{
+			Err:     "internal",
+			ErrDesc: "database operation failed",
 		})
 	}
 
-	u, err := a.DB.GetUser(c.Request().Context(), claims.UserID)
+	client, err := a.DB.GetClient(ctx, claims.ClientID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return c.JSON(http.StatusBadRequest, UserInfo{
-				Err:     "bad_request",
-				ErrDesc: "invalid user id",
-			})
+			return userInfoErr(c, http.StatusUnauthorized, "invalid_token",
+				"invalid client id")
 		}
 		return c.JSON(http.StatusInternalServerError, UserInfo{
 			Err:     "internal",
@@ -90,16 +114,75 @@ func (a API) UserInfo(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, UserInfo{
-		Email:     u.Email,
-		AvatarURL: u.AvatarUrl.String,
+	if !client.UserinfoSignedResponseAlg.Valid {
+		return c.JSON(http.StatusOK, UserInfo{
+			Sub:        u.ID,
+			UserClaims: newUserClaims(u, claims.Scopes),
+		})
+	}
+
+	tkn, err := a.sign(client.UserinfoSignedResponseAlg.String, userInfoClaims{
+		UserClaims: newUserClaims(u, claims.Scopes),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   a.BaseURL,
+			Subject:  u.ID,
+			Audience: jwt.ClaimStrings{client.ID},
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, UserInfo{
+			Err:     "internal",
+			ErrDesc: "failed to sign userinfo response",
+		})
+	}
+	return c.Blob(http.StatusOK, "application/jwt", []byte(tkn))
+}
+
+// userInfoTkn extracts the access token from the request along with the
+// scheme it was presented with. An empty token without an error means the
+// request carried no token at all.
+func userInfoTkn(c echo.Context) (string, string, error) {
+	header := c.Request().Header.Get("Authorization")
+	var form string
+	if c.Request().Method == http.MethodPost {
+		form = c.FormValue("access_token")
+	}
+
+	switch {
+	case header != "" && form != "":
+		return "", "", errors.New("access token must be presented using a single method")
+	case header != "":
+		return tknFromHeader(header)
+	default:
+		return form, schemeBearer, nil
+	}
+}
+
+// userInfoErr responds with a bearer token error as per RFC 6750 section
+// 3, or a DPoP error as per RFC 9449 section 7.1 for requests using DPoP.
+// Requests without any token only receive the authentication scheme.
+func userInfoErr(c echo.Context, status int, code, desc string) error {
+	challenge := schemeBearer + ` realm="ellipsis"`
+	if code == "invalid_dpop_proof" ||
+		strings.HasPrefix(c.Request().Header.Get("Authorization"), schemeDPoP+" ") {
+		challenge = fmt.Sprintf(`%s realm="ellipsis", algs=%q`,
+			schemeDPoP, strings.Join(dpopSigningAlgs, " "))
+	}
+	if code != "" {
+		challenge += fmt.Sprintf(`, error=%q, error_description=%q`, code, desc)
+	}
+	c.Response().Header().Set("WWW-Authenticate", challenge)
+	return c.JSON(status, UserInfo{
+		Err:     code,
+		ErrDesc: desc,
 	})
 }
 
-func tknFromHeader(h string) (string, error) {
+func tknFromHeader(h string) (string, string, error) {
 	parts := strings.Split(h, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return "", fmt.Errorf("invalid authorization header")
+	if len(parts) != 2 || (parts[0] != schemeBearer && parts[0] != schemeDPoP) {
+		return "", "", fmt.Errorf("invalid authorization header")
 	}
-	return parts[1], nil
+	return parts[1], parts[0], nil
 }
@@ -3,8 +3,11 @@ package util
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"database/sql"
 	"encoding/pem"
@@ -12,7 +15,11 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
+	"time"
 
 	"github.com/mileusna/useragent"
 	pswdValidator "github.com/wagslane/go-password-validator"
@@ -32,7 +39,9 @@ func GenerateRandom(n int) (string, error) {
 	return string(ret), nil
 }
 
-func PEMToEd25519PrivKey(data []byte) (ed25519.PrivateKey, error) {
+// PEMToPrivKey parses a PKCS #8 private key. Ed25519, RSA and ECDSA keys
+// are supported.
+func PEMToPrivKey(data []byte) (crypto.Signer, error) {
 	block, _ := pem.Decode(data)
 	if block == nil || block.Type != "PRIVATE KEY" {
 		return nil, fmt.Errorf("invalid PEM block")
@@ -41,14 +50,20 @@ func PEMToEd25519PrivKey(data []byte) (ed25519.PrivateKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse private key")
 	}
-	ed25519Priv, ok := key.(ed25519.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("failed to parse private key")
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
 	}
-	return ed25519Priv, nil
+	return nil, fmt.Errorf("unsupported private key type")
 }
 
-func PEMToEd25519PubKey(data []byte) (ed25519.PublicKey, error) {
+// PEMToPubKey parses a PKIX public key. Ed25519, RSA and ECDSA keys are
+// supported.
+func PEMToPubKey(data []byte) (crypto.PublicKey, error) {
 	block, _ := pem.Decode(data)
 	if block == nil || block.Type != "PUBLIC KEY" {
 		return nil, fmt.Errorf("invalid PEM block")
@@ -57,11 +72,15 @@ func PEMToEd25519PubKey(data []byte) (ed25519.PublicKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse public key")
 	}
-	ed25519Pub, ok := key.(ed25519.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("failed to parse public key")
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return key, nil
+	case *rsa.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		return key, nil
 	}
-	return ed25519Pub, nil
+	return nil, fmt.Errorf("unsupported public key type")
 }
 
 type Fingerprint struct {
@@ -145,3 +164,122 @@ func ReadURL(ctx context.Context, url string) (io.ReadSeeker, error) {
 
 	return bytes.NewReader(data), nil
 }
+
+// ReadPublicURL is like ReadURL, but meant for URLs supplied by third
+// parties. The URL must use https and may only resolve to public
+// addresses. It fails when the response body is larger than max bytes.
+func ReadPublicURL(ctx context.Context, rawURL string, max int64) (io.ReadSeeker, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+	if u.Scheme != "https" {
+		return nil, errors.New("url must use https")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new request: %w", err)
+	}
+
+	res, err := publicClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("file larger than %d bytes", max)
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// ErrNonPublicAddress is returned when a URL supplied by a third party
+// points to a loopback, private, link-local or otherwise internal address.
+var ErrNonPublicAddress = errors.New("url must point to a public address")
+
+// publicClient only connects to public addresses, including when following
+// redirects.
+var publicClient = &http.Client{
+	Timeout: time.Second * 7,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: time.Second * 5,
+			// runs once the address is resolved, right before connecting
+			Control: func(_, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+					return ErrNonPublicAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: time.Second * 5,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return errors.New("redirect to a non https url")
+		}
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		return nil
+	},
+}
+
+// ValidatePublicURL checks that a URL supplied by a third party uses https
+// and that its host only resolves to public addresses. URLs are checked
+// again when fetched, since DNS records may change in between.
+func ValidatePublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return errors.New("url must use https")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return errors.New("failed to resolve url host")
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return ErrNonPublicAddress
+		}
+	}
+	return nil
+}
+
+// special purpose IPv4 ranges not covered by the net.IP helpers
+var nonPublicNets = []*net.IPNet{
+	// "this network" (RFC 791)
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	// shared address space (RFC 6598)
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+	// benchmarking (RFC 2544)
+	{IP: net.IPv4(198, 18, 0, 0), Mask: net.CIDRMask(15, 32)},
+}
+
+func isPublicIP(ip net.IP) bool {
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -23,7 +23,9 @@ func NewSqlite(c SqliteConfig) (*sql.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate sqlite config: %w", err)
 	}
-	url := fmt.Sprintf("file:%s", c.Path)
+	// sqlite only enforces foreign keys, and hence ON DELETE actions, when
+	// enabled on every connection
+	url := fmt.Sprintf("file:%s?_foreign_keys=on", c.Path)
 	return sql.Open("sqlite3", url)
 }
 
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// IsDuplicateKey reports whether err is caused by an insert violating a
+// primary key or unique constraint.
+func IsDuplicateKey(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		// ER_DUP_ENTRY
+		return myErr.Number == 1062
+	}
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		return liteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+			liteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	// libsql only forwards the message of the remote sqlite error
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -19,7 +19,7 @@ var usage string
 
 func init() {
 	log.SetFlags(0)
-	usage = fmt.Sprintf("%s [sessions|codes]", os.Args[0])
+	usage = fmt.Sprintf("%s [sessions|codes|tokens|logouts]", os.Args[0])
 }
 
 func main() {
@@ -86,6 +86,37 @@ func main() {
 		if err != nil {
 			log.Fatalf("failed to delete expired auth codes: %s", err.Error())
 		}
+		err = q.DeleteExpiredDeviceCodes(ctx)
+		if err != nil {
+			log.Fatalf("failed to delete expired device codes: %s", err.Error())
+		}
+		err = q.DeleteExpiredPushedAuthzRequests(ctx)
+		if err != nil {
+			log.Fatalf("failed to delete expired pushed authorization requests: %s", err.Error())
+		}
+	case "tokens":
+		err := q.DeleteExpiredRefreshTokens(ctx)
+		if err != nil {
+			log.Fatalf("failed to delete expired refresh tokens: %s", err.Error())
+		}
+		err = q.DeleteExpiredAccessTokens(ctx)
+		if err != nil {
+			log.Fatalf("failed to delete expired access tokens: %s", err.Error())
+		}
+		err = q.DeleteExpiredDPoPProofs(ctx)
+		if err != nil {
+			log.Fatalf("failed to delete expired DPoP proofs: %s", err.Error())
+		}
+		err = q.DeleteExpiredUsedJTIs(ctx)
+		if err != nil {
+			log.Fatalf("failed to delete expired jti records: %s", err.Error())
+		}
+	case "logouts":
+		// keep a week worth of delivery history around for the console
+		err := q.DeleteStaleBackchannelLogouts(ctx, time.Now().Add(-time.Hour*24*7))
+		if err != nil {
+			log.Fatalf("failed to delete stale back-channel logouts: %s", err.Error())
+		}
 	default:
 		log.Fatalf("unknown argument. Usage: %s", usage)
 	}
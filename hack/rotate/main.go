@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/murtaza-u/ellipsis/api/util"
+	"github.com/murtaza-u/ellipsis/internal/conf"
+)
+
+const defaultConfPath = "/etc/ellipsis/config.yaml"
+
+var usage string
+
+func init() {
+	log.SetFlags(0)
+	usage = fmt.Sprintf("%s [generate [EdDSA|RS256|ES256]|promote|prune]", os.Args[0])
+}
+
+// Rotating keys without invalidating outstanding tokens is a three step
+// process:
+//
+//  1. generate: create a new key and publish it as the next key
+//  2. promote: once relying parties have refreshed their cached JWKS, sign
+//     using the next keys. Each replaces the active key of the same
+//     algorithm, which is kept as a previous key.
+//  3. prune: once the tokens signed using the previous keys have expired,
+//     stop publishing them.
+//
+// The server must be restarted for the changes to take effect.
+func main() {
+	if len(os.Args) < 2 || len(os.Args) > 3 {
+		log.Fatalf("invalid number of arguments. Usage: %s", usage)
+	}
+
+	path := os.Getenv("ELLIPSIS_CONFIG")
+	if path == "" {
+		path = defaultConfPath
+	}
+
+	c, err := conf.New(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if c.KeyStore == "" {
+		log.Fatal("missing key store")
+	}
+
+	spec, err := readSpec(c.KeyStore)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch os.Args[1] {
+	case "generate":
+		alg := conf.AlgEdDSA
+		if len(os.Args) == 3 {
+			alg = os.Args[2]
+		}
+		kid, err := generate(c.KeyStore, alg)
+		if err != nil {
+			log.Fatalf("failed to generate key: %s", err.Error())
+		}
+		spec.Next = append(spec.Next, kid)
+		log.Printf("generated key %q", kid)
+	case "promote":
+		if len(spec.Next) == 0 {
+			log.Fatalf("no next key to promote. Run %s generate first", os.Args[0])
+		}
+		for _, kid := range spec.Next {
+			if err := promote(c.KeyStore, spec, kid); err != nil {
+				log.Fatalf("failed to promote key %q: %s", kid, err.Error())
+			}
+			log.Printf("promoted key %q", kid)
+		}
+		spec.Next = nil
+	case "prune":
+		for _, kid := range spec.Previous {
+			log.Printf("pruned key %q", kid)
+		}
+		spec.Previous = nil
+	default:
+		log.Fatalf("unknown argument. Usage: %s", usage)
+	}
+
+	err = conf.WriteKeySetSpec(c.KeyStore, *spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readSpec reads the key set file. Key stores holding only the legacy
+// ed25519 key pair are migrated to a key set.
+func readSpec(dir string) (*conf.KeySetSpec, error) {
+	spec, err := conf.ReadKeySetSpec(dir)
+	if err != nil {
+		return nil, err
+	}
+	if spec != nil {
+		return spec, nil
+	}
+
+	for suffix, perm := range map[string]os.FileMode{"": 0600, ".pub": 0644} {
+		data, err := os.ReadFile(filepath.Join(dir, "ed25519"+suffix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read legacy key: %w", err)
+		}
+		err = os.WriteFile(filepath.Join(dir, conf.LegacyKeyID+suffix), data, perm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate legacy key: %w", err)
+		}
+	}
+	return &conf.KeySetSpec{Active: conf.KeyIDs{conf.LegacyKeyID}}, nil
+}
+
+// promote makes kid the active key for its algorithm. The key it replaces
+// becomes a previous key.
+func promote(dir string, spec *conf.KeySetSpec, kid string) error {
+	alg, err := keyAlg(dir, kid)
+	if err != nil {
+		return err
+	}
+	for i, active := range spec.Active {
+		activeAlg, err := keyAlg(dir, active)
+		if err != nil {
+			return err
+		}
+		if activeAlg == alg {
+			spec.Previous = append([]string{active}, spec.Previous...)
+			spec.Active[i] = kid
+			return nil
+		}
+	}
+	spec.Active = append(spec.Active, kid)
+	return nil
+}
+
+func keyAlg(dir, kid string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, kid+".pub"))
+	if err != nil {
+		return "", err
+	}
+	pub, err := util.PEMToPubKey(data)
+	if err != nil {
+		return "", err
+	}
+	return conf.KeyAlg(pub)
+}
+
+// generate creates a new key pair for the given algorithm in the key
+// store and returns its id.
+func generate(dir, alg string) (string, error) {
+	var (
+		priv   crypto.Signer
+		err    error
+		prefix string
+	)
+	switch alg {
+	case conf.AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(nil)
+		prefix = "ed25519"
+	case conf.AlgRS256:
+		priv, err = rsa.GenerateKey(rand.Reader, 3072)
+		prefix = "rsa"
+	case conf.AlgES256:
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		prefix = "ecdsa"
+	default:
+		return "", fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	if err != nil {
+		return "", err
+	}
+	kid := fmt.Sprintf("%s-%s", prefix, time.Now().UTC().Format("20060102150405"))
+
+	b, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", err
+	}
+	block := &pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: b,
+	}
+	err = os.WriteFile(filepath.Join(dir, kid), pem.EncodeToMemory(block), 0600)
+	if err != nil {
+		return "", err
+	}
+
+	b, err = x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		return "", err
+	}
+	block = &pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: b,
+	}
+	err = os.WriteFile(filepath.Join(dir, kid+".pub"), pem.EncodeToMemory(block), 0644)
+	if err != nil {
+		return "", err
+	}
+	return kid, nil
+}
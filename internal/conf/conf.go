@@ -1,31 +1,28 @@
 package conf
 
 import (
-	"crypto/ed25519"
 	"fmt"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 
-	"github.com/murtaza-u/ellipsis/api/util"
-
 	"gopkg.in/yaml.v3"
 )
 
 type C struct {
-	JsonLogger           bool      `yaml:"jsonLogger"`
-	RateLimiting         bool      `yaml:"rateLimiting"`
-	BaseURL              string    `yaml:"baseURL"`
-	Port                 uint16    `yaml:"port"`
-	KeyStore             string    `yaml:"keyStore"`
-	SessionEncryptionKey string    `yaml:"sessionEncryptionKey"`
-	DB                   DB        `yaml:"db"`
-	Providers            Providers `yaml:"providers"`
-	S3                   S3        `yaml:"s3"`
-	Captcha              Captcha   `yaml:"captcha"`
-
-	Key Key
+	JsonLogger           bool         `yaml:"jsonLogger"`
+	RateLimiting         bool         `yaml:"rateLimiting"`
+	BaseURL              string       `yaml:"baseURL"`
+	Port                 uint16       `yaml:"port"`
+	KeyStore             string       `yaml:"keyStore"`
+	SessionEncryptionKey string       `yaml:"sessionEncryptionKey"`
+	DB                   DB           `yaml:"db"`
+	Providers            Providers    `yaml:"providers"`
+	S3                   S3           `yaml:"s3"`
+	Captcha              Captcha      `yaml:"captcha"`
+	Registration         Registration `yaml:"registration"`
+
+	Keys KeySet
 }
 
 type DB struct {
@@ -68,15 +65,16 @@ type S3 struct {
 	Region string `yaml:"region"`
 }
 
-type Key struct {
-	Priv ed25519.PrivateKey
-	Pub  ed25519.PublicKey
-}
-
 type Captcha struct {
 	Turnstile Turnstile `yaml:"turnstile"`
 }
 
+// Registration configures dynamic client registration (RFC 7591).
+type Registration struct {
+	Enable             bool   `yaml:"enable"`
+	InitialAccessToken string `yaml:"initialAccessToken"`
+}
+
 type Turnstile struct {
 	Enable    bool   `yaml:"enable"`
 	SiteKey   string `yaml:"siteKey"`
@@ -197,47 +195,9 @@ func (c *C) Validate() error {
 		}
 	}
 
-	return nil
-}
-
-func (c *C) readKeysFromStore() error {
-	// check if `keystore` directory exists
-	if c.KeyStore == "" {
-		return fmt.Errorf("missing key store")
-	}
-	info, err := os.Stat(c.KeyStore)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("key store %q does not exist", c.KeyStore)
-		}
-		return fmt.Errorf("failed to access key store")
-	}
-	if !info.IsDir() {
-		return fmt.Errorf("key store %q is not a directory", c.KeyStore)
-	}
-
-	// read and parse private key
-	data, err := os.ReadFile(filepath.Join(c.KeyStore, "ed25519"))
-	if err != nil {
-		return fmt.Errorf("failed to read ed25519 priv key: %w", err)
-	}
-
-	priv, err := util.PEMToEd25519PrivKey(data)
-	if err != nil {
-		return fmt.Errorf("failed to read ed25519 priv key: %w", err)
-	}
-
-	// read and parse public key
-	data, err = os.ReadFile(filepath.Join(c.KeyStore, "ed25519.pub"))
-	if err != nil {
-		return fmt.Errorf("failed to read ed25519 pub key: %w", err)
+	if c.Registration.Enable && c.Registration.InitialAccessToken == "" {
+		return fmt.Errorf("missing initial access token for client registration")
 	}
-	pub, err := util.PEMToEd25519PubKey(data)
-	if err != nil {
-		return fmt.Errorf("failed to read ed25519 pub key: %w", err)
-	}
-
-	c.Key = Key{Priv: priv, Pub: pub}
 
 	return nil
 }
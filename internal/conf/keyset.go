@@ -0,0 +1,259 @@
+package conf
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/murtaza-u/ellipsis/api/util"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KeySetFile lists the keys, by id, found in the key store. Each key
+	// is stored as <kid> (private key) and <kid>.pub (public key).
+	KeySetFile = "keyset.yaml"
+	// LegacyKeyID is the id of the single ed25519 key used by key stores
+	// without a key set file.
+	LegacyKeyID = "ed25519-key-1"
+)
+
+// signing algorithms
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+type Key struct {
+	ID   string
+	Alg  string
+	Priv crypto.Signer
+	Pub  crypto.PublicKey
+}
+
+// KeySet holds the signing keys. Tokens are signed using the active key
+// for the requested algorithm. Next keys are published ahead of their
+// activation so that relying parties have them cached by then, and
+// previous keys remain published until the tokens they signed expire.
+type KeySet struct {
+	Active   []Key
+	Next     []Key
+	Previous []Key
+}
+
+// KeyIDs is a list of key ids. A single id may be written as a scalar.
+type KeyIDs []string
+
+func (k *KeyIDs) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*k = KeyIDs{n.Value}
+		return nil
+	}
+	var ids []string
+	if err := n.Decode(&ids); err != nil {
+		return err
+	}
+	*k = ids
+	return nil
+}
+
+// KeySetSpec is the content of the key set file. There is at most one
+// active key per algorithm.
+type KeySetSpec struct {
+	Active   KeyIDs   `yaml:"active"`
+	Next     []string `yaml:"next,omitempty"`
+	Previous []string `yaml:"previous,omitempty"`
+}
+
+// Published returns every key that must be published in the JWKS.
+func (s KeySet) Published() []Key {
+	keys := append([]Key{}, s.Active...)
+	keys = append(keys, s.Next...)
+	return append(keys, s.Previous...)
+}
+
+// Lookup returns the key with the given id.
+func (s KeySet) Lookup(kid string) (Key, bool) {
+	for _, k := range s.Published() {
+		if k.ID == kid {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Signer returns the active key for the given algorithm.
+func (s KeySet) Signer(alg string) (Key, bool) {
+	for _, k := range s.Active {
+		if k.Alg == alg {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Algs returns the algorithms tokens can be signed with.
+func (s KeySet) Algs() []string {
+	algs := make([]string, 0, len(s.Active))
+	for _, k := range s.Active {
+		algs = append(algs, k.Alg)
+	}
+	return algs
+}
+
+// KeyAlg returns the JWS algorithm used with the given public key. RSA
+// keys are used with RS256 and ECDSA keys must be on the P-256 curve.
+func KeyAlg(pub crypto.PublicKey) (string, error) {
+	switch pub := pub.(type) {
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return "", fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		return AlgRS256, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return "", fmt.Errorf("ECDSA keys must use the P-256 curve")
+		}
+		return AlgES256, nil
+	}
+	return "", fmt.Errorf("unsupported key type")
+}
+
+// ReadKeySetSpec reads the key set file from the key store. A key store
+// without one is treated as holding the legacy ed25519 key pair.
+func ReadKeySetSpec(dir string) (*KeySetSpec, error) {
+	data, err := os.ReadFile(filepath.Join(dir, KeySetFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read key set: %w", err)
+	}
+	spec := new(KeySetSpec)
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal key set: %w", err)
+	}
+	if len(spec.Active) == 0 {
+		return nil, fmt.Errorf("key set has no active key")
+	}
+	return spec, nil
+}
+
+// WriteKeySetSpec writes the key set file to the key store.
+func WriteKeySetSpec(dir string, spec KeySetSpec) error {
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key set: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, KeySetFile), data, 0600)
+}
+
+func (c *C) readKeysFromStore() error {
+	// check if `keystore` directory exists
+	if c.KeyStore == "" {
+		return fmt.Errorf("missing key store")
+	}
+	info, err := os.Stat(c.KeyStore)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("key store %q does not exist", c.KeyStore)
+		}
+		return fmt.Errorf("failed to access key store")
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("key store %q is not a directory", c.KeyStore)
+	}
+
+	spec, err := ReadKeySetSpec(c.KeyStore)
+	if err != nil {
+		return err
+	}
+
+	if spec == nil {
+		key, err := readKey(
+			LegacyKeyID,
+			filepath.Join(c.KeyStore, "ed25519"),
+			filepath.Join(c.KeyStore, "ed25519.pub"),
+		)
+		if err != nil {
+			return err
+		}
+		c.Keys = KeySet{Active: []Key{*key}}
+		return nil
+	}
+
+	c.Keys = KeySet{}
+	for _, kid := range spec.Active {
+		key, err := readKey(
+			kid,
+			filepath.Join(c.KeyStore, kid),
+			filepath.Join(c.KeyStore, kid+".pub"),
+		)
+		if err != nil {
+			return err
+		}
+		if _, ok := c.Keys.Signer(key.Alg); ok {
+			return fmt.Errorf("multiple active keys for %s", key.Alg)
+		}
+		c.Keys.Active = append(c.Keys.Active, *key)
+	}
+
+	// only the public half of inactive keys is needed
+	for _, kid := range spec.Next {
+		key, err := readKey(kid, "", filepath.Join(c.KeyStore, kid+".pub"))
+		if err != nil {
+			return err
+		}
+		c.Keys.Next = append(c.Keys.Next, *key)
+	}
+	for _, kid := range spec.Previous {
+		key, err := readKey(kid, "", filepath.Join(c.KeyStore, kid+".pub"))
+		if err != nil {
+			return err
+		}
+		c.Keys.Previous = append(c.Keys.Previous, *key)
+	}
+
+	return nil
+}
+
+// readKey reads a key pair from disk. The private key is skipped if
+// privPath is empty.
+func readKey(kid, privPath, pubPath string) (*Key, error) {
+	data, err := os.ReadFile(pubPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pub key: %w", err)
+	}
+	pub, err := util.PEMToPubKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pub key %q: %w", pubPath, err)
+	}
+	alg, err := KeyAlg(pub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pub key %q: %w", pubPath, err)
+	}
+	key := &Key{ID: kid, Alg: alg, Pub: pub}
+
+	if privPath == "" {
+		return key, nil
+	}
+	data, err = os.ReadFile(privPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read priv key: %w", err)
+	}
+	key.Priv, err = util.PEMToPrivKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read priv key %q: %w", privPath, err)
+	}
+	return key, nil
+}
@@ -9,14 +9,43 @@ import (
 	"time"
 )
 
-type AuthorizationCode struct {
+type AccessToken struct {
 	ID        string
-	UserID    string
 	ClientID  string
+	UserID    sql.NullString
+	SessionID sql.NullString
+	Subject   string
+	Audience  string
 	Scopes    string
-	Os        sql.NullString
-	Browser   sql.NullString
-	ExpiresAt sql.NullTime
+	Act       sql.NullString
+	Jkt       sql.NullString
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type ApiResource struct {
+	ID              string
+	Name            string
+	Identifier      string
+	Scopes          sql.NullString
+	TokenExpiration int64
+	CreatedAt       time.Time
+}
+
+type AuthorizationCode struct {
+	ID                  string
+	UserID              string
+	ClientID            string
+	Scopes              string
+	CodeChallenge       sql.NullString
+	CodeChallengeMethod sql.NullString
+	Nonce               sql.NullString
+	AuthTime            sql.NullTime
+	Os                  sql.NullString
+	Browser             sql.NullString
+	ExpiresAt           sql.NullTime
+	SessionID           sql.NullString
+	Resource            sql.NullString
 }
 
 type AuthorizationHistory struct {
@@ -25,16 +54,80 @@ type AuthorizationHistory struct {
 	AuthorizedAt time.Time
 }
 
+type BackchannelLogoutOutbox struct {
+	ID            string
+	ClientID      string
+	UserID        string
+	SessionID     string
+	Status        string
+	Attempts      int64
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	DeliveredAt   sql.NullTime
+	CreatedAt     time.Time
+}
+
 type Client struct {
-	ID                   string
-	SecretHash           string
-	Name                 string
-	PictureUrl           sql.NullString
-	AuthCallbackUrls     string
-	LogoutCallbackUrls   string
-	BackchannelLogoutUrl sql.NullString
-	TokenExpiration      int64
-	CreatedAt            time.Time
+	ID                                 string
+	SecretHash                         sql.NullString
+	Name                               string
+	PictureUrl                         sql.NullString
+	AuthCallbackUrls                   string
+	LogoutCallbackUrls                 string
+	BackchannelLogoutUrl               sql.NullString
+	TokenExpiration                    int64
+	ClientType                         string
+	AllowedScopes                      sql.NullString
+	TokenEndpointAuthMethod            string
+	Jwks                               sql.NullString
+	JwksUri                            sql.NullString
+	RequireSignedRequestObject         bool
+	RequirePushedAuthorizationRequests bool
+	RegistrationAccessTokenHash        sql.NullString
+	IDTokenSignedResponseAlg           string
+	FrontchannelLogoutUri              sql.NullString
+	UserinfoSignedResponseAlg          sql.NullString
+	AccessTokenFormat                  string
+	RequireDpop                        bool
+	CreatedAt                          time.Time
+}
+
+type DeviceCode struct {
+	ID           string
+	UserCode     string
+	ClientID     string
+	UserID       sql.NullString
+	Scopes       string
+	Status       string
+	PollInterval int64
+	LastPolledAt sql.NullTime
+	AuthTime     sql.NullTime
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+type DpopProof struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+type PushedAuthorizationRequest struct {
+	ID        string
+	ClientID  string
+	Params    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type RefreshToken struct {
+	ID        string
+	SessionID string
+	Scopes    string
+	Resource  sql.NullString
+	Jkt       sql.NullString
+	Used      bool
+	CreatedAt time.Time
+	ExpiresAt time.Time
 }
 
 type Session struct {
@@ -45,6 +138,21 @@ type Session struct {
 	ExpiresAt time.Time
 	Os        sql.NullString
 	Browser   sql.NullString
+	AuthTime  sql.NullTime
+	ParentID  sql.NullString
+}
+
+type TokenExchangePolicy struct {
+	ID            string
+	ClientID      string
+	ApiResourceID string
+	Scopes        sql.NullString
+	CreatedAt     time.Time
+}
+
+type UsedJti struct {
+	ID        string
+	ExpiresAt time.Time
 }
 
 type User struct {
@@ -53,5 +161,16 @@ type User struct {
 	AvatarUrl      sql.NullString
 	HashedPassword sql.NullString
 	IsAdmin        bool
+	EmailVerified  bool
+	Name           sql.NullString
+	GivenName      sql.NullString
+	FamilyName     sql.NullString
+	PhoneNumber    sql.NullString
+	StreetAddress  sql.NullString
+	Locality       sql.NullString
+	Region         sql.NullString
+	PostalCode     sql.NullString
+	Country        sql.NullString
+	UpdatedAt      sql.NullTime
 	CreatedAt      time.Time
 }
@@ -11,28 +11,115 @@ import (
 	"time"
 )
 
+const createAPIResource = `-- name: CreateAPIResource :execresult
+INSERT INTO api_resource (
+    id,
+    name,
+    identifier,
+    scopes,
+    token_expiration
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type CreateAPIResourceParams struct {
+	ID              string
+	Name            string
+	Identifier      string
+	Scopes          sql.NullString
+	TokenExpiration int64
+}
+
+func (q *Queries) CreateAPIResource(ctx context.Context, arg CreateAPIResourceParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAPIResource,
+		arg.ID,
+		arg.Name,
+		arg.Identifier,
+		arg.Scopes,
+		arg.TokenExpiration,
+	)
+}
+
+const createAccessToken = `-- name: CreateAccessToken :execresult
+INSERT INTO access_token (
+    id,
+    client_id,
+    user_id,
+    session_id,
+    subject,
+    audience,
+    scopes,
+    act,
+    jkt,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateAccessTokenParams struct {
+	ID        string
+	ClientID  string
+	UserID    sql.NullString
+	SessionID sql.NullString
+	Subject   string
+	Audience  string
+	Scopes    string
+	Act       sql.NullString
+	Jkt       sql.NullString
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateAccessToken(ctx context.Context, arg CreateAccessTokenParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAccessToken,
+		arg.ID,
+		arg.ClientID,
+		arg.UserID,
+		arg.SessionID,
+		arg.Subject,
+		arg.Audience,
+		arg.Scopes,
+		arg.Act,
+		arg.Jkt,
+		arg.ExpiresAt,
+	)
+}
+
 const createAuthzCode = `-- name: CreateAuthzCode :execresult
 INSERT INTO authorization_code (
     id,
     user_id,
     client_id,
     scopes,
+    code_challenge,
+    code_challenge_method,
+    nonce,
+    auth_time,
     os,
     browser,
-    expires_at
+    expires_at,
+    session_id,
+    resource
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
 type CreateAuthzCodeParams struct {
-	ID        string
-	UserID    string
-	ClientID  string
-	Scopes    string
-	Os        sql.NullString
-	Browser   sql.NullString
-	ExpiresAt sql.NullTime
+	ID                  string
+	UserID              string
+	ClientID            string
+	Scopes              string
+	CodeChallenge       sql.NullString
+	CodeChallengeMethod sql.NullString
+	Nonce               sql.NullString
+	AuthTime            sql.NullTime
+	Os                  sql.NullString
+	Browser             sql.NullString
+	ExpiresAt           sql.NullTime
+	SessionID           sql.NullString
+	Resource            sql.NullString
 }
 
 func (q *Queries) CreateAuthzCode(ctx context.Context, arg CreateAuthzCodeParams) (sql.Result, error) {
@@ -41,9 +128,15 @@ func (q *Queries) CreateAuthzCode(ctx context.Context, arg CreateAuthzCodeParams
 		arg.UserID,
 		arg.ClientID,
 		arg.Scopes,
+		arg.CodeChallenge,
+		arg.CodeChallengeMethod,
+		arg.Nonce,
+		arg.AuthTime,
 		arg.Os,
 		arg.Browser,
 		arg.ExpiresAt,
+		arg.SessionID,
+		arg.Resource,
 	)
 }
 
@@ -60,6 +153,36 @@ func (q *Queries) CreateAuthzHistory(ctx context.Context, arg CreateAuthzHistory
 	return q.db.ExecContext(ctx, createAuthzHistory, arg.UserID, arg.ClientID)
 }
 
+const createBackchannelLogout = `-- name: CreateBackchannelLogout :execresult
+INSERT INTO backchannel_logout_outbox (
+    id,
+    client_id,
+    user_id,
+    session_id,
+    next_attempt_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type CreateBackchannelLogoutParams struct {
+	ID            string
+	ClientID      string
+	UserID        string
+	SessionID     string
+	NextAttemptAt time.Time
+}
+
+func (q *Queries) CreateBackchannelLogout(ctx context.Context, arg CreateBackchannelLogoutParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createBackchannelLogout,
+		arg.ID,
+		arg.ClientID,
+		arg.UserID,
+		arg.SessionID,
+		arg.NextAttemptAt,
+	)
+}
+
 const createClient = `-- name: CreateClient :execresult
 INSERT INTO client (
     id,
@@ -69,21 +192,47 @@ INSERT INTO client (
     logout_callback_urls,
     picture_url,
     backchannel_logout_url,
-    token_expiration
+    token_expiration,
+    client_type,
+    allowed_scopes,
+    token_endpoint_auth_method,
+    jwks,
+    jwks_uri,
+    require_signed_request_object,
+    require_pushed_authorization_requests,
+    registration_access_token_hash,
+    id_token_signed_response_alg,
+    frontchannel_logout_uri,
+    userinfo_signed_response_alg,
+    access_token_format,
+    require_dpop
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
 type CreateClientParams struct {
-	ID                   string
-	SecretHash           string
-	Name                 string
-	AuthCallbackUrls     string
-	LogoutCallbackUrls   string
-	PictureUrl           sql.NullString
-	BackchannelLogoutUrl sql.NullString
-	TokenExpiration      int64
+	ID                                 string
+	SecretHash                         sql.NullString
+	Name                               string
+	AuthCallbackUrls                   string
+	LogoutCallbackUrls                 string
+	PictureUrl                         sql.NullString
+	BackchannelLogoutUrl               sql.NullString
+	TokenExpiration                    int64
+	ClientType                         string
+	AllowedScopes                      sql.NullString
+	TokenEndpointAuthMethod            string
+	Jwks                               sql.NullString
+	JwksUri                            sql.NullString
+	RequireSignedRequestObject         bool
+	RequirePushedAuthorizationRequests bool
+	RegistrationAccessTokenHash        sql.NullString
+	IDTokenSignedResponseAlg           string
+	FrontchannelLogoutUri              sql.NullString
+	UserinfoSignedResponseAlg          sql.NullString
+	AccessTokenFormat                  string
+	RequireDpop                        bool
 }
 
 func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (sql.Result, error) {
@@ -96,6 +245,130 @@ func (q *Queries) CreateClient(ctx context.Context, arg CreateClientParams) (sql
 		arg.PictureUrl,
 		arg.BackchannelLogoutUrl,
 		arg.TokenExpiration,
+		arg.ClientType,
+		arg.AllowedScopes,
+		arg.TokenEndpointAuthMethod,
+		arg.Jwks,
+		arg.JwksUri,
+		arg.RequireSignedRequestObject,
+		arg.RequirePushedAuthorizationRequests,
+		arg.RegistrationAccessTokenHash,
+		arg.IDTokenSignedResponseAlg,
+		arg.FrontchannelLogoutUri,
+		arg.UserinfoSignedResponseAlg,
+		arg.AccessTokenFormat,
+		arg.RequireDpop,
+	)
+}
+
+const createDPoPProof = `-- name: CreateDPoPProof :execresult
+INSERT INTO dpop_proof (
+    id,
+    expires_at
+) VALUES (
+    ?, ?
+)
+`
+
+type CreateDPoPProofParams struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateDPoPProof(ctx context.Context, arg CreateDPoPProofParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createDPoPProof,
+		arg.ID,
+		arg.ExpiresAt,
+	)
+}
+
+const createDeviceCode = `-- name: CreateDeviceCode :execresult
+INSERT INTO device_code (
+    id,
+    user_code,
+    client_id,
+    scopes,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type CreateDeviceCodeParams struct {
+	ID        string
+	UserCode  string
+	ClientID  string
+	Scopes    string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateDeviceCode(ctx context.Context, arg CreateDeviceCodeParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createDeviceCode,
+		arg.ID,
+		arg.UserCode,
+		arg.ClientID,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+}
+
+const createPushedAuthzRequest = `-- name: CreatePushedAuthzRequest :execresult
+INSERT INTO pushed_authorization_request (
+    id,
+    client_id,
+    params,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?
+)
+`
+
+type CreatePushedAuthzRequestParams struct {
+	ID        string
+	ClientID  string
+	Params    string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreatePushedAuthzRequest(ctx context.Context, arg CreatePushedAuthzRequestParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createPushedAuthzRequest,
+		arg.ID,
+		arg.ClientID,
+		arg.Params,
+		arg.ExpiresAt,
+	)
+}
+
+const createRefreshToken = `-- name: CreateRefreshToken :execresult
+INSERT INTO refresh_token (
+    id,
+    session_id,
+    scopes,
+    resource,
+    jkt,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateRefreshTokenParams struct {
+	ID        string
+	SessionID string
+	Scopes    string
+	Resource  sql.NullString
+	Jkt       sql.NullString
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createRefreshToken,
+		arg.ID,
+		arg.SessionID,
+		arg.Scopes,
+		arg.Resource,
+		arg.Jkt,
+		arg.ExpiresAt,
 	)
 }
 
@@ -106,9 +379,11 @@ INSERT INTO session (
     client_id,
     expires_at,
     os,
-    browser
+    browser,
+    auth_time,
+    parent_id
 ) VALUES (
-    ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
@@ -119,6 +394,8 @@ type CreateSessionParams struct {
 	ExpiresAt time.Time
 	Os        sql.NullString
 	Browser   sql.NullString
+	AuthTime  sql.NullTime
+	ParentID  sql.NullString
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (sql.Result, error) {
@@ -129,20 +406,79 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (s
 		arg.ExpiresAt,
 		arg.Os,
 		arg.Browser,
+		arg.AuthTime,
+		arg.ParentID,
 	)
 }
 
-const createUser = `-- name: CreateUser :execresult
-INSERT INTO user (id, email, hashed_password, avatar_url) VALUES (
+const createTokenExchangePolicy = `-- name: CreateTokenExchangePolicy :execresult
+INSERT INTO token_exchange_policy (
+    id,
+    client_id,
+    api_resource_id,
+    scopes
+) VALUES (
     ?, ?, ?, ?
 )
 `
 
+type CreateTokenExchangePolicyParams struct {
+	ID            string
+	ClientID      string
+	ApiResourceID string
+	Scopes        sql.NullString
+}
+
+func (q *Queries) CreateTokenExchangePolicy(ctx context.Context, arg CreateTokenExchangePolicyParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createTokenExchangePolicy,
+		arg.ID,
+		arg.ClientID,
+		arg.ApiResourceID,
+		arg.Scopes,
+	)
+}
+
+const createUsedJTI = `-- name: CreateUsedJTI :execresult
+INSERT INTO used_jti (
+    id,
+    expires_at
+) VALUES (
+    ?, ?
+)
+`
+
+type CreateUsedJTIParams struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateUsedJTI(ctx context.Context, arg CreateUsedJTIParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createUsedJTI,
+		arg.ID,
+		arg.ExpiresAt,
+	)
+}
+
+const createUser = `-- name: CreateUser :execresult
+INSERT INTO user (
+    id,
+    email,
+    hashed_password,
+    avatar_url,
+    email_verified,
+    name
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
 type CreateUserParams struct {
 	ID             string
 	Email          string
 	HashedPassword sql.NullString
 	AvatarUrl      sql.NullString
+	EmailVerified  bool
+	Name           sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error) {
@@ -151,16 +487,47 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Res
 		arg.Email,
 		arg.HashedPassword,
 		arg.AvatarUrl,
+		arg.EmailVerified,
+		arg.Name,
 	)
 }
 
-const deleteAuthzCode = `-- name: DeleteAuthzCode :exec
+const deleteAPIResource = `-- name: DeleteAPIResource :exec
+DELETE FROM api_resource
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAPIResource(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAPIResource, id)
+	return err
+}
+
+const deleteAccessToken = `-- name: DeleteAccessToken :exec
+DELETE FROM access_token
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAccessToken(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAccessToken, id)
+	return err
+}
+
+const deleteAuthzCode = `-- name: DeleteAuthzCode :execresult
 DELETE FROM authorization_code
 WHERE id = ?
 `
 
-func (q *Queries) DeleteAuthzCode(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteAuthzCode, id)
+func (q *Queries) DeleteAuthzCode(ctx context.Context, id string) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteAuthzCode, id)
+}
+
+const deleteChildSessions = `-- name: DeleteChildSessions :exec
+DELETE FROM session
+WHERE parent_id = ?
+`
+
+func (q *Queries) DeleteChildSessions(ctx context.Context, parentID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, deleteChildSessions, parentID)
 	return err
 }
 
@@ -174,6 +541,25 @@ func (q *Queries) DeleteClient(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteDeviceCode = `-- name: DeleteDeviceCode :execresult
+DELETE FROM device_code
+WHERE id = ?
+`
+
+func (q *Queries) DeleteDeviceCode(ctx context.Context, id string) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteDeviceCode, id)
+}
+
+const deleteExpiredAccessTokens = `-- name: DeleteExpiredAccessTokens :exec
+DELETE FROM access_token
+WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredAccessTokens(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredAccessTokens)
+	return err
+}
+
 const deleteExpiredAuthzCode = `-- name: DeleteExpiredAuthzCode :exec
 DELETE FROM authorization_code
 WHERE expires_at <= CURRENT_TIMESTAMP
@@ -184,6 +570,46 @@ func (q *Queries) DeleteExpiredAuthzCode(ctx context.Context) error {
 	return err
 }
 
+const deleteExpiredDPoPProofs = `-- name: DeleteExpiredDPoPProofs :exec
+DELETE FROM dpop_proof
+WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredDPoPProofs(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredDPoPProofs)
+	return err
+}
+
+const deleteExpiredDeviceCodes = `-- name: DeleteExpiredDeviceCodes :exec
+DELETE FROM device_code
+WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredDeviceCodes(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredDeviceCodes)
+	return err
+}
+
+const deleteExpiredPushedAuthzRequests = `-- name: DeleteExpiredPushedAuthzRequests :exec
+DELETE FROM pushed_authorization_request
+WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredPushedAuthzRequests(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredPushedAuthzRequests)
+	return err
+}
+
+const deleteExpiredRefreshTokens = `-- name: DeleteExpiredRefreshTokens :exec
+DELETE FROM refresh_token
+WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredRefreshTokens(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredRefreshTokens)
+	return err
+}
+
 const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
 DELETE FROM session
 WHERE expires_at <= CURRENT_TIMESTAMP
@@ -194,6 +620,25 @@ func (q *Queries) DeleteExpiredSessions(ctx context.Context) error {
 	return err
 }
 
+const deleteExpiredUsedJTIs = `-- name: DeleteExpiredUsedJTIs :exec
+DELETE FROM used_jti
+WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredUsedJTIs(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredUsedJTIs)
+	return err
+}
+
+const deletePushedAuthzRequest = `-- name: DeletePushedAuthzRequest :execresult
+DELETE FROM pushed_authorization_request
+WHERE id = ?
+`
+
+func (q *Queries) DeletePushedAuthzRequest(ctx context.Context, id string) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deletePushedAuthzRequest, id)
+}
+
 const deleteSession = `-- name: DeleteSession :exec
 DELETE FROM session
 WHERE id = ?
@@ -204,8 +649,151 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteStaleBackchannelLogouts = `-- name: DeleteStaleBackchannelLogouts :exec
+DELETE FROM backchannel_logout_outbox
+WHERE status != 'pending' AND created_at <= ?
+`
+
+func (q *Queries) DeleteStaleBackchannelLogouts(ctx context.Context, createdAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleBackchannelLogouts, createdAt)
+	return err
+}
+
+const deleteTokenExchangePolicy = `-- name: DeleteTokenExchangePolicy :exec
+DELETE FROM token_exchange_policy
+WHERE id = ? AND client_id = ?
+`
+
+type DeleteTokenExchangePolicyParams struct {
+	ID       string
+	ClientID string
+}
+
+func (q *Queries) DeleteTokenExchangePolicy(ctx context.Context, arg DeleteTokenExchangePolicyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTokenExchangePolicy,
+		arg.ID,
+		arg.ClientID,
+	)
+	return err
+}
+
+const getAPIResource = `-- name: GetAPIResource :one
+SELECT id, name, identifier, scopes, token_expiration, created_at FROM api_resource
+WHERE id = ?
+`
+
+func (q *Queries) GetAPIResource(ctx context.Context, id string) (ApiResource, error) {
+	row := q.db.QueryRowContext(ctx, getAPIResource, id)
+	var i ApiResource
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Identifier,
+		&i.Scopes,
+		&i.TokenExpiration,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIResourceByIdentifier = `-- name: GetAPIResourceByIdentifier :one
+SELECT id, name, identifier, scopes, token_expiration, created_at FROM api_resource
+WHERE identifier = ?
+`
+
+func (q *Queries) GetAPIResourceByIdentifier(ctx context.Context, identifier string) (ApiResource, error) {
+	row := q.db.QueryRowContext(ctx, getAPIResourceByIdentifier, identifier)
+	var i ApiResource
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Identifier,
+		&i.Scopes,
+		&i.TokenExpiration,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIResourceByName = `-- name: GetAPIResourceByName :one
+SELECT id, name, identifier, scopes, token_expiration, created_at FROM api_resource
+WHERE name = ?
+`
+
+func (q *Queries) GetAPIResourceByName(ctx context.Context, name string) (ApiResource, error) {
+	row := q.db.QueryRowContext(ctx, getAPIResourceByName, name)
+	var i ApiResource
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Identifier,
+		&i.Scopes,
+		&i.TokenExpiration,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIResources = `-- name: GetAPIResources :many
+SELECT id, name, identifier, scopes, token_expiration, created_at FROM api_resource
+`
+
+func (q *Queries) GetAPIResources(ctx context.Context) ([]ApiResource, error) {
+	rows, err := q.db.QueryContext(ctx, getAPIResources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiResource
+	for rows.Next() {
+		var i ApiResource
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Identifier,
+			&i.Scopes,
+			&i.TokenExpiration,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAccessToken = `-- name: GetAccessToken :one
+SELECT id, client_id, user_id, session_id, subject, audience, scopes, act, jkt, expires_at, created_at FROM access_token
+WHERE id = ?
+`
+
+func (q *Queries) GetAccessToken(ctx context.Context, id string) (AccessToken, error) {
+	row := q.db.QueryRowContext(ctx, getAccessToken, id)
+	var i AccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.ClientID,
+		&i.UserID,
+		&i.SessionID,
+		&i.Subject,
+		&i.Audience,
+		&i.Scopes,
+		&i.Act,
+		&i.Jkt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getAuthzCode = `-- name: GetAuthzCode :one
-SELECT id, user_id, client_id, scopes, os, browser, expires_at FROM authorization_code
+SELECT id, user_id, client_id, scopes, code_challenge, code_challenge_method, nonce, auth_time, os, browser, expires_at, session_id, resource FROM authorization_code
 WHERE id = ?
 `
 
@@ -217,9 +805,15 @@ func (q *Queries) GetAuthzCode(ctx context.Context, id string) (AuthorizationCod
 		&i.UserID,
 		&i.ClientID,
 		&i.Scopes,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+		&i.Nonce,
+		&i.AuthTime,
 		&i.Os,
 		&i.Browser,
 		&i.ExpiresAt,
+		&i.SessionID,
+		&i.Resource,
 	)
 	return i, err
 }
@@ -234,15 +828,110 @@ type GetAuthzHistoryParams struct {
 	ClientID string
 }
 
-func (q *Queries) GetAuthzHistory(ctx context.Context, arg GetAuthzHistoryParams) (AuthorizationHistory, error) {
-	row := q.db.QueryRowContext(ctx, getAuthzHistory, arg.UserID, arg.ClientID)
-	var i AuthorizationHistory
-	err := row.Scan(&i.UserID, &i.ClientID, &i.AuthorizedAt)
-	return i, err
+func (q *Queries) GetAuthzHistory(ctx context.Context, arg GetAuthzHistoryParams) (AuthorizationHistory, error) {
+	row := q.db.QueryRowContext(ctx, getAuthzHistory, arg.UserID, arg.ClientID)
+	var i AuthorizationHistory
+	err := row.Scan(&i.UserID, &i.ClientID, &i.AuthorizedAt)
+	return i, err
+}
+
+const getBackchannelLogoutsForClientID = `-- name: GetBackchannelLogoutsForClientID :many
+SELECT id, client_id, user_id, session_id, status, attempts, last_error, next_attempt_at, delivered_at, created_at FROM backchannel_logout_outbox
+WHERE client_id = ?
+ORDER BY created_at DESC
+LIMIT 25
+`
+
+func (q *Queries) GetBackchannelLogoutsForClientID(ctx context.Context, clientID string) ([]BackchannelLogoutOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, getBackchannelLogoutsForClientID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BackchannelLogoutOutbox
+	for rows.Next() {
+		var i BackchannelLogoutOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClientID,
+			&i.UserID,
+			&i.SessionID,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChildSessionsWithClient = `-- name: GetChildSessionsWithClient :many
+SELECT
+    session.id,
+    session.user_id,
+    client.id as client_id,
+    client.backchannel_logout_url,
+    client.frontchannel_logout_uri
+FROM
+    session
+INNER JOIN
+    client
+ON
+    session.client_id = client.id
+WHERE
+    session.parent_id = ?
+`
+
+type GetChildSessionsWithClientRow struct {
+	ID                    string
+	UserID                string
+	ClientID              string
+	BackchannelLogoutUrl  sql.NullString
+	FrontchannelLogoutUri sql.NullString
+}
+
+func (q *Queries) GetChildSessionsWithClient(ctx context.Context, parentID sql.NullString) ([]GetChildSessionsWithClientRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChildSessionsWithClient, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChildSessionsWithClientRow
+	for rows.Next() {
+		var i GetChildSessionsWithClientRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ClientID,
+			&i.BackchannelLogoutUrl,
+			&i.FrontchannelLogoutUri,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getClient = `-- name: GetClient :one
-SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, created_at FROM client
+SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, client_type, allowed_scopes, token_endpoint_auth_method, jwks, jwks_uri, require_signed_request_object, require_pushed_authorization_requests, registration_access_token_hash, id_token_signed_response_alg, frontchannel_logout_uri, userinfo_signed_response_alg, access_token_format, require_dpop, created_at FROM client
 WHERE id = ?
 `
 
@@ -258,13 +947,26 @@ func (q *Queries) GetClient(ctx context.Context, id string) (Client, error) {
 		&i.LogoutCallbackUrls,
 		&i.BackchannelLogoutUrl,
 		&i.TokenExpiration,
+		&i.ClientType,
+		&i.AllowedScopes,
+		&i.TokenEndpointAuthMethod,
+		&i.Jwks,
+		&i.JwksUri,
+		&i.RequireSignedRequestObject,
+		&i.RequirePushedAuthorizationRequests,
+		&i.RegistrationAccessTokenHash,
+		&i.IDTokenSignedResponseAlg,
+		&i.FrontchannelLogoutUri,
+		&i.UserinfoSignedResponseAlg,
+		&i.AccessTokenFormat,
+		&i.RequireDpop,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getClientByName = `-- name: GetClientByName :one
-SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, created_at FROM client
+SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, client_type, allowed_scopes, token_endpoint_auth_method, jwks, jwks_uri, require_signed_request_object, require_pushed_authorization_requests, registration_access_token_hash, id_token_signed_response_alg, frontchannel_logout_uri, userinfo_signed_response_alg, access_token_format, require_dpop, created_at FROM client
 WHERE name = ?
 `
 
@@ -280,13 +982,26 @@ func (q *Queries) GetClientByName(ctx context.Context, name string) (Client, err
 		&i.LogoutCallbackUrls,
 		&i.BackchannelLogoutUrl,
 		&i.TokenExpiration,
+		&i.ClientType,
+		&i.AllowedScopes,
+		&i.TokenEndpointAuthMethod,
+		&i.Jwks,
+		&i.JwksUri,
+		&i.RequireSignedRequestObject,
+		&i.RequirePushedAuthorizationRequests,
+		&i.RegistrationAccessTokenHash,
+		&i.IDTokenSignedResponseAlg,
+		&i.FrontchannelLogoutUri,
+		&i.UserinfoSignedResponseAlg,
+		&i.AccessTokenFormat,
+		&i.RequireDpop,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getClientByNameForUnmatchingID = `-- name: GetClientByNameForUnmatchingID :one
-SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, created_at FROM client
+SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, client_type, allowed_scopes, token_endpoint_auth_method, jwks, jwks_uri, require_signed_request_object, require_pushed_authorization_requests, registration_access_token_hash, id_token_signed_response_alg, frontchannel_logout_uri, userinfo_signed_response_alg, access_token_format, require_dpop, created_at FROM client
 WHERE name = ? AND id != ?
 `
 
@@ -307,13 +1022,26 @@ func (q *Queries) GetClientByNameForUnmatchingID(ctx context.Context, arg GetCli
 		&i.LogoutCallbackUrls,
 		&i.BackchannelLogoutUrl,
 		&i.TokenExpiration,
+		&i.ClientType,
+		&i.AllowedScopes,
+		&i.TokenEndpointAuthMethod,
+		&i.Jwks,
+		&i.JwksUri,
+		&i.RequireSignedRequestObject,
+		&i.RequirePushedAuthorizationRequests,
+		&i.RegistrationAccessTokenHash,
+		&i.IDTokenSignedResponseAlg,
+		&i.FrontchannelLogoutUri,
+		&i.UserinfoSignedResponseAlg,
+		&i.AccessTokenFormat,
+		&i.RequireDpop,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getClients = `-- name: GetClients :many
-SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, created_at FROM client
+SELECT id, secret_hash, name, picture_url, auth_callback_urls, logout_callback_urls, backchannel_logout_url, token_expiration, client_type, allowed_scopes, token_endpoint_auth_method, jwks, jwks_uri, require_signed_request_object, require_pushed_authorization_requests, registration_access_token_hash, id_token_signed_response_alg, frontchannel_logout_uri, userinfo_signed_response_alg, access_token_format, require_dpop, created_at FROM client
 `
 
 func (q *Queries) GetClients(ctx context.Context) ([]Client, error) {
@@ -334,6 +1062,19 @@ func (q *Queries) GetClients(ctx context.Context) ([]Client, error) {
 			&i.LogoutCallbackUrls,
 			&i.BackchannelLogoutUrl,
 			&i.TokenExpiration,
+			&i.ClientType,
+			&i.AllowedScopes,
+			&i.TokenEndpointAuthMethod,
+			&i.Jwks,
+			&i.JwksUri,
+			&i.RequireSignedRequestObject,
+			&i.RequirePushedAuthorizationRequests,
+			&i.RegistrationAccessTokenHash,
+			&i.IDTokenSignedResponseAlg,
+			&i.FrontchannelLogoutUri,
+			&i.UserinfoSignedResponseAlg,
+			&i.AccessTokenFormat,
+			&i.RequireDpop,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -349,8 +1090,194 @@ func (q *Queries) GetClients(ctx context.Context) ([]Client, error) {
 	return items, nil
 }
 
+const getDeviceCode = `-- name: GetDeviceCode :one
+SELECT id, user_code, client_id, user_id, scopes, status, poll_interval, last_polled_at, auth_time, created_at, expires_at FROM device_code
+WHERE id = ?
+`
+
+func (q *Queries) GetDeviceCode(ctx context.Context, id string) (DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getDeviceCode, id)
+	var i DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserCode,
+		&i.ClientID,
+		&i.UserID,
+		&i.Scopes,
+		&i.Status,
+		&i.PollInterval,
+		&i.LastPolledAt,
+		&i.AuthTime,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getDeviceCodeByUserCode = `-- name: GetDeviceCodeByUserCode :one
+SELECT id, user_code, client_id, user_id, scopes, status, poll_interval, last_polled_at, auth_time, created_at, expires_at FROM device_code
+WHERE user_code = ?
+`
+
+func (q *Queries) GetDeviceCodeByUserCode(ctx context.Context, userCode string) (DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getDeviceCodeByUserCode, userCode)
+	var i DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserCode,
+		&i.ClientID,
+		&i.UserID,
+		&i.Scopes,
+		&i.Status,
+		&i.PollInterval,
+		&i.LastPolledAt,
+		&i.AuthTime,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getDueBackchannelLogouts = `-- name: GetDueBackchannelLogouts :many
+SELECT
+    backchannel_logout_outbox.id,
+    backchannel_logout_outbox.client_id,
+    backchannel_logout_outbox.user_id,
+    backchannel_logout_outbox.session_id,
+    backchannel_logout_outbox.attempts,
+    client.backchannel_logout_url,
+    client.id_token_signed_response_alg
+FROM
+    backchannel_logout_outbox
+INNER JOIN
+    client
+ON
+    backchannel_logout_outbox.client_id = client.id
+WHERE
+    backchannel_logout_outbox.status = 'pending'
+    AND backchannel_logout_outbox.next_attempt_at <= ?
+ORDER BY
+    backchannel_logout_outbox.next_attempt_at
+LIMIT 25
+`
+
+type GetDueBackchannelLogoutsRow struct {
+	ID                       string
+	ClientID                 string
+	UserID                   string
+	SessionID                string
+	Attempts                 int64
+	BackchannelLogoutUrl     sql.NullString
+	IDTokenSignedResponseAlg string
+}
+
+func (q *Queries) GetDueBackchannelLogouts(ctx context.Context, nextAttemptAt time.Time) ([]GetDueBackchannelLogoutsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDueBackchannelLogouts, nextAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDueBackchannelLogoutsRow
+	for rows.Next() {
+		var i GetDueBackchannelLogoutsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClientID,
+			&i.UserID,
+			&i.SessionID,
+			&i.Attempts,
+			&i.BackchannelLogoutUrl,
+			&i.IDTokenSignedResponseAlg,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPushedAuthzRequest = `-- name: GetPushedAuthzRequest :one
+SELECT id, client_id, params, created_at, expires_at FROM pushed_authorization_request
+WHERE id = ?
+`
+
+func (q *Queries) GetPushedAuthzRequest(ctx context.Context, id string) (PushedAuthorizationRequest, error) {
+	row := q.db.QueryRowContext(ctx, getPushedAuthzRequest, id)
+	var i PushedAuthorizationRequest
+	err := row.Scan(
+		&i.ID,
+		&i.ClientID,
+		&i.Params,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getRefreshTokenWithSession = `-- name: GetRefreshTokenWithSession :one
+SELECT
+    refresh_token.id,
+    refresh_token.session_id,
+    refresh_token.scopes,
+    refresh_token.resource,
+    refresh_token.jkt,
+    refresh_token.used,
+    refresh_token.expires_at,
+    session.user_id,
+    session.client_id,
+    session.auth_time,
+    session.created_at
+FROM
+    refresh_token
+INNER JOIN
+    session
+ON
+    refresh_token.session_id = session.id
+WHERE
+    refresh_token.id = ?
+`
+
+type GetRefreshTokenWithSessionRow struct {
+	ID        string
+	SessionID string
+	Scopes    string
+	Resource  sql.NullString
+	Jkt       sql.NullString
+	Used      bool
+	ExpiresAt time.Time
+	UserID    string
+	ClientID  sql.NullString
+	AuthTime  sql.NullTime
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetRefreshTokenWithSession(ctx context.Context, id string) (GetRefreshTokenWithSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenWithSession, id)
+	var i GetRefreshTokenWithSessionRow
+	err := row.Scan(
+		&i.ID,
+		&i.SessionID,
+		&i.Scopes,
+		&i.Resource,
+		&i.Jkt,
+		&i.Used,
+		&i.ExpiresAt,
+		&i.UserID,
+		&i.ClientID,
+		&i.AuthTime,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getSession = `-- name: GetSession :one
-SELECT id, user_id, client_id, created_at, expires_at, os, browser FROM session
+SELECT id, user_id, client_id, created_at, expires_at, os, browser, auth_time, parent_id FROM session
 WHERE id = ? LIMIT 1
 `
 
@@ -365,6 +1292,8 @@ func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
 		&i.ExpiresAt,
 		&i.Os,
 		&i.Browser,
+		&i.AuthTime,
+		&i.ParentID,
 	)
 	return i, err
 }
@@ -372,10 +1301,12 @@ func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
 const getSessionWithClient = `-- name: GetSessionWithClient :one
 SELECT
     session.id,
+    session.parent_id,
     client.id as client_id,
     client.name as client_name,
     client.logout_callback_urls,
-    client.backchannel_logout_url
+    client.backchannel_logout_url,
+    client.frontchannel_logout_uri
 FROM
     session
 INNER JOIN
@@ -387,11 +1318,13 @@ WHERE
 `
 
 type GetSessionWithClientRow struct {
-	ID                   string
-	ClientID             string
-	ClientName           string
-	LogoutCallbackUrls   string
-	BackchannelLogoutUrl sql.NullString
+	ID                    string
+	ParentID              sql.NullString
+	ClientID              string
+	ClientName            string
+	LogoutCallbackUrls    string
+	BackchannelLogoutUrl  sql.NullString
+	FrontchannelLogoutUri sql.NullString
 }
 
 func (q *Queries) GetSessionWithClient(ctx context.Context, id string) (GetSessionWithClientRow, error) {
@@ -399,10 +1332,12 @@ func (q *Queries) GetSessionWithClient(ctx context.Context, id string) (GetSessi
 	var i GetSessionWithClientRow
 	err := row.Scan(
 		&i.ID,
+		&i.ParentID,
 		&i.ClientID,
 		&i.ClientName,
 		&i.LogoutCallbackUrls,
 		&i.BackchannelLogoutUrl,
+		&i.FrontchannelLogoutUri,
 	)
 	return i, err
 }
@@ -467,10 +1402,12 @@ func (q *Queries) GetSessionWithClientForUserID(ctx context.Context, userID stri
 const getSessionWithOptionalClient = `-- name: GetSessionWithOptionalClient :one
 SELECT
     session.id,
+    session.user_id,
     client.id as client_id,
     client.name as client_name,
     client.logout_callback_urls,
-    client.backchannel_logout_url
+    client.backchannel_logout_url,
+    client.frontchannel_logout_uri
 FROM
     session
 LEFT JOIN
@@ -482,11 +1419,13 @@ WHERE
 `
 
 type GetSessionWithOptionalClientRow struct {
-	ID                   string
-	ClientID             sql.NullString
-	ClientName           sql.NullString
-	LogoutCallbackUrls   sql.NullString
-	BackchannelLogoutUrl sql.NullString
+	ID                    string
+	UserID                string
+	ClientID              sql.NullString
+	ClientName            sql.NullString
+	LogoutCallbackUrls    sql.NullString
+	BackchannelLogoutUrl  sql.NullString
+	FrontchannelLogoutUri sql.NullString
 }
 
 func (q *Queries) GetSessionWithOptionalClient(ctx context.Context, id string) (GetSessionWithOptionalClientRow, error) {
@@ -494,10 +1433,12 @@ func (q *Queries) GetSessionWithOptionalClient(ctx context.Context, id string) (
 	var i GetSessionWithOptionalClientRow
 	err := row.Scan(
 		&i.ID,
+		&i.UserID,
 		&i.ClientID,
 		&i.ClientName,
 		&i.LogoutCallbackUrls,
 		&i.BackchannelLogoutUrl,
+		&i.FrontchannelLogoutUri,
 	)
 	return i, err
 }
@@ -505,7 +1446,9 @@ func (q *Queries) GetSessionWithOptionalClient(ctx context.Context, id string) (
 const getSessionWithUser = `-- name: GetSessionWithUser :one
 SELECT
     session.id,
+    session.created_at,
     session.expires_at,
+    session.auth_time,
     user.id as user_id,
     user.email,
     user.avatar_url,
@@ -522,7 +1465,9 @@ WHERE
 
 type GetSessionWithUserRow struct {
 	ID        string
+	CreatedAt time.Time
 	ExpiresAt time.Time
+	AuthTime  sql.NullTime
 	UserID    string
 	Email     string
 	AvatarUrl sql.NullString
@@ -534,7 +1479,9 @@ func (q *Queries) GetSessionWithUser(ctx context.Context, id string) (GetSession
 	var i GetSessionWithUserRow
 	err := row.Scan(
 		&i.ID,
+		&i.CreatedAt,
 		&i.ExpiresAt,
+		&i.AuthTime,
 		&i.UserID,
 		&i.Email,
 		&i.AvatarUrl,
@@ -543,8 +1490,87 @@ func (q *Queries) GetSessionWithUser(ctx context.Context, id string) (GetSession
 	return i, err
 }
 
+const getTokenExchangePoliciesForClientID = `-- name: GetTokenExchangePoliciesForClientID :many
+SELECT
+    token_exchange_policy.id,
+    token_exchange_policy.scopes,
+    token_exchange_policy.created_at,
+    api_resource.name as api_resource_name,
+    api_resource.identifier as api_resource_identifier
+FROM
+    token_exchange_policy
+INNER JOIN
+    api_resource
+ON
+    token_exchange_policy.api_resource_id = api_resource.id
+WHERE
+    token_exchange_policy.client_id = ?
+ORDER BY
+    token_exchange_policy.created_at
+`
+
+type GetTokenExchangePoliciesForClientIDRow struct {
+	ID                    string
+	Scopes                sql.NullString
+	CreatedAt             time.Time
+	ApiResourceName       string
+	ApiResourceIdentifier string
+}
+
+func (q *Queries) GetTokenExchangePoliciesForClientID(ctx context.Context, clientID string) ([]GetTokenExchangePoliciesForClientIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTokenExchangePoliciesForClientID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTokenExchangePoliciesForClientIDRow
+	for rows.Next() {
+		var i GetTokenExchangePoliciesForClientIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.ApiResourceName,
+			&i.ApiResourceIdentifier,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTokenExchangePolicy = `-- name: GetTokenExchangePolicy :one
+SELECT id, client_id, api_resource_id, scopes, created_at FROM token_exchange_policy
+WHERE client_id = ? AND api_resource_id = ?
+`
+
+type GetTokenExchangePolicyParams struct {
+	ClientID      string
+	ApiResourceID string
+}
+
+func (q *Queries) GetTokenExchangePolicy(ctx context.Context, arg GetTokenExchangePolicyParams) (TokenExchangePolicy, error) {
+	row := q.db.QueryRowContext(ctx, getTokenExchangePolicy, arg.ClientID, arg.ApiResourceID)
+	var i TokenExchangePolicy
+	err := row.Scan(
+		&i.ID,
+		&i.ClientID,
+		&i.ApiResourceID,
+		&i.Scopes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, email, avatar_url, hashed_password, is_admin, created_at FROM user
+SELECT id, email, avatar_url, hashed_password, is_admin, email_verified, name, given_name, family_name, phone_number, street_address, locality, region, postal_code, country, updated_at, created_at FROM user
 WHERE id = ? LIMIT 1
 `
 
@@ -557,6 +1583,17 @@ func (q *Queries) GetUser(ctx context.Context, id string) (User, error) {
 		&i.AvatarUrl,
 		&i.HashedPassword,
 		&i.IsAdmin,
+		&i.EmailVerified,
+		&i.Name,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.PhoneNumber,
+		&i.StreetAddress,
+		&i.Locality,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.UpdatedAt,
 		&i.CreatedAt,
 	)
 	return i, err
@@ -581,7 +1618,7 @@ func (q *Queries) GetUserAndClientCount(ctx context.Context) (GetUserAndClientCo
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, avatar_url, hashed_password, is_admin, created_at FROM user
+SELECT id, email, avatar_url, hashed_password, is_admin, email_verified, name, given_name, family_name, phone_number, street_address, locality, region, postal_code, country, updated_at, created_at FROM user
 WHERE email = ? LIMIT 1
 `
 
@@ -594,13 +1631,24 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.AvatarUrl,
 		&i.HashedPassword,
 		&i.IsAdmin,
+		&i.EmailVerified,
+		&i.Name,
+		&i.GivenName,
+		&i.FamilyName,
+		&i.PhoneNumber,
+		&i.StreetAddress,
+		&i.Locality,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.UpdatedAt,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-SELECT id, email, avatar_url, hashed_password, is_admin, created_at FROM user
+SELECT id, email, avatar_url, hashed_password, is_admin, email_verified, name, given_name, family_name, phone_number, street_address, locality, region, postal_code, country, updated_at, created_at FROM user
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -618,6 +1666,17 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.AvatarUrl,
 			&i.HashedPassword,
 			&i.IsAdmin,
+			&i.EmailVerified,
+			&i.Name,
+			&i.GivenName,
+			&i.FamilyName,
+			&i.PhoneNumber,
+			&i.StreetAddress,
+			&i.Locality,
+			&i.Region,
+			&i.PostalCode,
+			&i.Country,
+			&i.UpdatedAt,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -633,6 +1692,94 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const markRefreshTokenUsed = `-- name: MarkRefreshTokenUsed :execresult
+UPDATE refresh_token
+SET used = true
+WHERE id = ? AND used = false
+`
+
+func (q *Queries) MarkRefreshTokenUsed(ctx context.Context, id string) (sql.Result, error) {
+	return q.db.ExecContext(ctx, markRefreshTokenUsed, id)
+}
+
+const updateAPIResource = `-- name: UpdateAPIResource :exec
+UPDATE api_resource
+SET name = ?,
+    identifier = ?,
+    scopes = ?,
+    token_expiration = ?
+WHERE id = ?
+`
+
+type UpdateAPIResourceParams struct {
+	Name            string
+	Identifier      string
+	Scopes          sql.NullString
+	TokenExpiration int64
+	ID              string
+}
+
+func (q *Queries) UpdateAPIResource(ctx context.Context, arg UpdateAPIResourceParams) error {
+	_, err := q.db.ExecContext(ctx, updateAPIResource,
+		arg.Name,
+		arg.Identifier,
+		arg.Scopes,
+		arg.TokenExpiration,
+		arg.ID,
+	)
+	return err
+}
+
+const updateAuthzHistory = `-- name: UpdateAuthzHistory :exec
+UPDATE authorization_history
+SET authorized_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND client_id = ?
+`
+
+type UpdateAuthzHistoryParams struct {
+	UserID   string
+	ClientID string
+}
+
+func (q *Queries) UpdateAuthzHistory(ctx context.Context, arg UpdateAuthzHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, updateAuthzHistory,
+		arg.UserID,
+		arg.ClientID,
+	)
+	return err
+}
+
+const updateBackchannelLogout = `-- name: UpdateBackchannelLogout :exec
+UPDATE backchannel_logout_outbox
+SET status = ?,
+    attempts = ?,
+    last_error = ?,
+    next_attempt_at = ?,
+    delivered_at = ?
+WHERE id = ?
+`
+
+type UpdateBackchannelLogoutParams struct {
+	Status        string
+	Attempts      int64
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	DeliveredAt   sql.NullTime
+	ID            string
+}
+
+func (q *Queries) UpdateBackchannelLogout(ctx context.Context, arg UpdateBackchannelLogoutParams) error {
+	_, err := q.db.ExecContext(ctx, updateBackchannelLogout,
+		arg.Status,
+		arg.Attempts,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.DeliveredAt,
+		arg.ID,
+	)
+	return err
+}
+
 const updateClient = `-- name: UpdateClient :exec
 UPDATE client
 SET name = ?,
@@ -640,18 +1787,40 @@ SET name = ?,
     logout_callback_urls = ?,
     picture_url = ?,
     backchannel_logout_url = ?,
-    token_expiration = ?
+    token_expiration = ?,
+    allowed_scopes = ?,
+    token_endpoint_auth_method = ?,
+    jwks = ?,
+    jwks_uri = ?,
+    require_signed_request_object = ?,
+    require_pushed_authorization_requests = ?,
+    id_token_signed_response_alg = ?,
+    frontchannel_logout_uri = ?,
+    userinfo_signed_response_alg = ?,
+    access_token_format = ?,
+    require_dpop = ?
 WHERE id = ?
 `
 
 type UpdateClientParams struct {
-	Name                 string
-	AuthCallbackUrls     string
-	LogoutCallbackUrls   string
-	PictureUrl           sql.NullString
-	BackchannelLogoutUrl sql.NullString
-	TokenExpiration      int64
-	ID                   string
+	Name                               string
+	AuthCallbackUrls                   string
+	LogoutCallbackUrls                 string
+	PictureUrl                         sql.NullString
+	BackchannelLogoutUrl               sql.NullString
+	TokenExpiration                    int64
+	AllowedScopes                      sql.NullString
+	TokenEndpointAuthMethod            string
+	Jwks                               sql.NullString
+	JwksUri                            sql.NullString
+	RequireSignedRequestObject         bool
+	RequirePushedAuthorizationRequests bool
+	IDTokenSignedResponseAlg           string
+	FrontchannelLogoutUri              sql.NullString
+	UserinfoSignedResponseAlg          sql.NullString
+	AccessTokenFormat                  string
+	RequireDpop                        bool
+	ID                                 string
 }
 
 func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) error {
@@ -662,11 +1831,84 @@ func (q *Queries) UpdateClient(ctx context.Context, arg UpdateClientParams) erro
 		arg.PictureUrl,
 		arg.BackchannelLogoutUrl,
 		arg.TokenExpiration,
+		arg.AllowedScopes,
+		arg.TokenEndpointAuthMethod,
+		arg.Jwks,
+		arg.JwksUri,
+		arg.RequireSignedRequestObject,
+		arg.RequirePushedAuthorizationRequests,
+		arg.IDTokenSignedResponseAlg,
+		arg.FrontchannelLogoutUri,
+		arg.UserinfoSignedResponseAlg,
+		arg.AccessTokenFormat,
+		arg.RequireDpop,
+		arg.ID,
+	)
+	return err
+}
+
+const updateDeviceCodePoll = `-- name: UpdateDeviceCodePoll :exec
+UPDATE device_code
+SET poll_interval = ?,
+    last_polled_at = ?
+WHERE id = ?
+`
+
+type UpdateDeviceCodePollParams struct {
+	PollInterval int64
+	LastPolledAt sql.NullTime
+	ID           string
+}
+
+func (q *Queries) UpdateDeviceCodePoll(ctx context.Context, arg UpdateDeviceCodePollParams) error {
+	_, err := q.db.ExecContext(ctx, updateDeviceCodePoll,
+		arg.PollInterval,
+		arg.LastPolledAt,
 		arg.ID,
 	)
 	return err
 }
 
+const updateDeviceCodeStatus = `-- name: UpdateDeviceCodeStatus :execresult
+UPDATE device_code
+SET status = ?,
+    user_id = ?,
+    auth_time = ?
+WHERE user_code = ? AND status = 'pending'
+`
+
+type UpdateDeviceCodeStatusParams struct {
+	Status   string
+	UserID   sql.NullString
+	AuthTime sql.NullTime
+	UserCode string
+}
+
+func (q *Queries) UpdateDeviceCodeStatus(ctx context.Context, arg UpdateDeviceCodeStatusParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateDeviceCodeStatus,
+		arg.Status,
+		arg.UserID,
+		arg.AuthTime,
+		arg.UserCode,
+	)
+}
+
+const updateSessionExpiry = `-- name: UpdateSessionExpiry :exec
+UPDATE session
+SET expires_at = ?
+WHERE id = ?
+`
+
+type UpdateSessionExpiryParams struct {
+	ExpiresAt time.Time
+	ID        string
+}
+
+func (q *Queries) UpdateSessionExpiry(ctx context.Context, arg UpdateSessionExpiryParams) error {
+	_, err := q.db.ExecContext(ctx, updateSessionExpiry, arg.ExpiresAt, arg.ID)
+	return err
+}
+
 const updateUserAvatar = `-- name: UpdateUserAvatar :exec
 UPDATE user
 SET avatar_url = ?
@@ -698,3 +1940,49 @@ func (q *Queries) UpdateUserPasswordHash(ctx context.Context, arg UpdateUserPass
 	_, err := q.db.ExecContext(ctx, updateUserPasswordHash, arg.HashedPassword, arg.ID)
 	return err
 }
+
+const updateUserProfile = `-- name: UpdateUserProfile :exec
+UPDATE user
+SET name = ?,
+    given_name = ?,
+    family_name = ?,
+    phone_number = ?,
+    street_address = ?,
+    locality = ?,
+    region = ?,
+    postal_code = ?,
+    country = ?,
+    updated_at = ?
+WHERE id = ?
+`
+
+type UpdateUserProfileParams struct {
+	Name          sql.NullString
+	GivenName     sql.NullString
+	FamilyName    sql.NullString
+	PhoneNumber   sql.NullString
+	StreetAddress sql.NullString
+	Locality      sql.NullString
+	Region        sql.NullString
+	PostalCode    sql.NullString
+	Country       sql.NullString
+	UpdatedAt     sql.NullTime
+	ID            string
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfile,
+		arg.Name,
+		arg.GivenName,
+		arg.FamilyName,
+		arg.PhoneNumber,
+		arg.StreetAddress,
+		arg.Locality,
+		arg.Region,
+		arg.PostalCode,
+		arg.Country,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
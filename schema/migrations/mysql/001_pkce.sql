@@ -0,0 +1,3 @@
+ALTER TABLE authorization_code
+    ADD COLUMN code_challenge VARCHAR(128),
+    ADD COLUMN code_challenge_method VARCHAR(5);
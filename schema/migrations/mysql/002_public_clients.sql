@@ -0,0 +1,3 @@
+ALTER TABLE client
+    MODIFY secret_hash CHAR(97),
+    ADD COLUMN client_type VARCHAR(12) NOT NULL DEFAULT 'confidential';
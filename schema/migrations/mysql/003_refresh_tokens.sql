@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id CHAR(64) PRIMARY KEY,
+    session_id CHAR(25) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
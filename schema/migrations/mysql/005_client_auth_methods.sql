@@ -0,0 +1,4 @@
+ALTER TABLE client
+    ADD COLUMN token_endpoint_auth_method VARCHAR(30) NOT NULL DEFAULT 'client_secret_post',
+    ADD COLUMN jwks TEXT,
+    ADD COLUMN jwks_uri VARCHAR(100);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS device_code (
+    id CHAR(64) PRIMARY KEY,
+    user_code CHAR(8) NOT NULL UNIQUE,
+    client_id CHAR(25) NOT NULL,
+    user_id CHAR(25),
+    scopes VARCHAR(255) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    poll_interval BIGINT NOT NULL DEFAULT 5,
+    last_polled_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
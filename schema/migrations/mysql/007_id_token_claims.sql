@@ -0,0 +1,7 @@
+ALTER TABLE session ADD COLUMN auth_time TIMESTAMP NULL;
+
+ALTER TABLE authorization_code
+    ADD COLUMN nonce VARCHAR(255),
+    ADD COLUMN auth_time TIMESTAMP NULL;
+
+ALTER TABLE device_code ADD COLUMN auth_time TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN registration_access_token_hash CHAR(64);
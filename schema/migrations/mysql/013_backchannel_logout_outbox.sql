@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS backchannel_logout_outbox (
+    id CHAR(25) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    session_id CHAR(25) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error VARCHAR(255),
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+ALTER TABLE session
+    ADD COLUMN parent_id CHAR(25),
+    ADD FOREIGN KEY (parent_id) REFERENCES session(id) ON DELETE SET NULL;
+
+ALTER TABLE authorization_code ADD COLUMN session_id CHAR(25);
@@ -0,0 +1,12 @@
+ALTER TABLE user
+    ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN name VARCHAR(100),
+    ADD COLUMN given_name VARCHAR(50),
+    ADD COLUMN family_name VARCHAR(50),
+    ADD COLUMN phone_number VARCHAR(20),
+    ADD COLUMN street_address VARCHAR(255),
+    ADD COLUMN locality VARCHAR(50),
+    ADD COLUMN region VARCHAR(50),
+    ADD COLUMN postal_code VARCHAR(20),
+    ADD COLUMN country VARCHAR(50),
+    ADD COLUMN updated_at TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN userinfo_signed_response_alg VARCHAR(10);
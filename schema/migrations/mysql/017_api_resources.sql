@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_resource (
+    id CHAR(25) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    identifier VARCHAR(100) NOT NULL UNIQUE,
+    scopes VARCHAR(255),
+    token_expiration BIGINT NOT NULL DEFAULT 1800,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE authorization_code
+    MODIFY scopes VARCHAR(255) NOT NULL,
+    ADD COLUMN resource VARCHAR(100);
+
+ALTER TABLE refresh_token ADD COLUMN resource VARCHAR(100);
@@ -0,0 +1,15 @@
+ALTER TABLE client ADD COLUMN access_token_format VARCHAR(10) NOT NULL DEFAULT 'jwt';
+
+CREATE TABLE IF NOT EXISTS access_token (
+    id CHAR(64) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    user_id CHAR(25),
+    session_id CHAR(25),
+    subject VARCHAR(255) NOT NULL,
+    audience VARCHAR(100) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
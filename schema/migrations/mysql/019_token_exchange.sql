@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS token_exchange_policy (
+    id CHAR(25) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    api_resource_id CHAR(25) NOT NULL,
+    scopes VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, api_resource_id),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (api_resource_id) REFERENCES api_resource(id) ON DELETE CASCADE
+);
+
+ALTER TABLE access_token ADD COLUMN act VARCHAR(1000);
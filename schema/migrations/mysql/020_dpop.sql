@@ -0,0 +1,8 @@
+ALTER TABLE client ADD COLUMN require_dpop BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE refresh_token ADD COLUMN jkt CHAR(43);
+ALTER TABLE access_token ADD COLUMN jkt CHAR(43);
+
+CREATE TABLE IF NOT EXISTS dpop_proof (
+    id CHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
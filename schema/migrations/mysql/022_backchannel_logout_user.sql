@@ -0,0 +1,6 @@
+-- Deliveries queued before this migration do not know the user they
+-- belong to. Their logout tokens only identify the session.
+ALTER TABLE backchannel_logout_outbox
+    ADD COLUMN user_id CHAR(25) NOT NULL DEFAULT '' AFTER client_id;
+
+ALTER TABLE backchannel_logout_outbox ALTER COLUMN user_id DROP DEFAULT;
@@ -0,0 +1,2 @@
+ALTER TABLE authorization_code ADD COLUMN code_challenge TEXT;
+ALTER TABLE authorization_code ADD COLUMN code_challenge_method TEXT;
@@ -0,0 +1,49 @@
+-- sqlite can not drop a NOT NULL constraint, hence the client table is
+-- rebuilt. Foreign keys must be off, or dropping the old table would
+-- cascade to every table referencing it.
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+CREATE TABLE client_new (
+    id TEXT PRIMARY KEY,
+    secret_hash TEXT,
+    name TEXT NOT NULL UNIQUE,
+    picture_url TEXT,
+    auth_callback_urls TEXT NOT NULL,
+    logout_callback_urls TEXT NOT NULL,
+    backchannel_logout_url TEXT,
+    token_expiration bigint NOT NULL DEFAULT 28800,
+    client_type TEXT NOT NULL DEFAULT 'confidential',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO client_new (
+    id,
+    secret_hash,
+    name,
+    picture_url,
+    auth_callback_urls,
+    logout_callback_urls,
+    backchannel_logout_url,
+    token_expiration,
+    created_at
+)
+SELECT
+    id,
+    secret_hash,
+    name,
+    picture_url,
+    auth_callback_urls,
+    logout_callback_urls,
+    backchannel_logout_url,
+    token_expiration,
+    created_at
+FROM client;
+
+DROP TABLE client;
+ALTER TABLE client_new RENAME TO client;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
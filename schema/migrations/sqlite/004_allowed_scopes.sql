@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN allowed_scopes TEXT;
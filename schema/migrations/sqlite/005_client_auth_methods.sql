@@ -0,0 +1,3 @@
+ALTER TABLE client ADD COLUMN token_endpoint_auth_method TEXT NOT NULL DEFAULT 'client_secret_post';
+ALTER TABLE client ADD COLUMN jwks TEXT;
+ALTER TABLE client ADD COLUMN jwks_uri TEXT;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS device_code (
+    id TEXT PRIMARY KEY,
+    user_code TEXT NOT NULL UNIQUE,
+    client_id TEXT NOT NULL,
+    user_id TEXT,
+    scopes TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    poll_interval bigint NOT NULL DEFAULT 5,
+    last_polled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+ALTER TABLE session ADD COLUMN auth_time TIMESTAMP;
+ALTER TABLE authorization_code ADD COLUMN nonce TEXT;
+ALTER TABLE authorization_code ADD COLUMN auth_time TIMESTAMP;
+ALTER TABLE device_code ADD COLUMN auth_time TIMESTAMP;
@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN require_signed_request_object BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,10 @@
+ALTER TABLE client ADD COLUMN require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS pushed_authorization_request (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    params TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
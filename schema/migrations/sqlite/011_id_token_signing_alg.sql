@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN id_token_signed_response_alg TEXT NOT NULL DEFAULT 'EdDSA';
@@ -0,0 +1 @@
+ALTER TABLE client ADD COLUMN frontchannel_logout_uri TEXT;
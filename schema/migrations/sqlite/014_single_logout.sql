@@ -0,0 +1,2 @@
+ALTER TABLE session ADD COLUMN parent_id TEXT REFERENCES session(id) ON DELETE SET NULL;
+ALTER TABLE authorization_code ADD COLUMN session_id TEXT;
@@ -0,0 +1,11 @@
+ALTER TABLE user ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE user ADD COLUMN name TEXT;
+ALTER TABLE user ADD COLUMN given_name TEXT;
+ALTER TABLE user ADD COLUMN family_name TEXT;
+ALTER TABLE user ADD COLUMN phone_number TEXT;
+ALTER TABLE user ADD COLUMN street_address TEXT;
+ALTER TABLE user ADD COLUMN locality TEXT;
+ALTER TABLE user ADD COLUMN region TEXT;
+ALTER TABLE user ADD COLUMN postal_code TEXT;
+ALTER TABLE user ADD COLUMN country TEXT;
+ALTER TABLE user ADD COLUMN updated_at TIMESTAMP;
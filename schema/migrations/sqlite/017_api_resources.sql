@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_resource (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    identifier TEXT NOT NULL UNIQUE,
+    scopes TEXT,
+    token_expiration bigint NOT NULL DEFAULT 1800,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE authorization_code ADD COLUMN resource TEXT;
+ALTER TABLE refresh_token ADD COLUMN resource TEXT;
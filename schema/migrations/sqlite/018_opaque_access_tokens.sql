@@ -0,0 +1,15 @@
+ALTER TABLE client ADD COLUMN access_token_format TEXT NOT NULL DEFAULT 'jwt';
+
+CREATE TABLE IF NOT EXISTS access_token (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    user_id TEXT,
+    session_id TEXT,
+    subject TEXT NOT NULL,
+    audience TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS token_exchange_policy (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    api_resource_id TEXT NOT NULL,
+    scopes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (client_id, api_resource_id),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (api_resource_id) REFERENCES api_resource(id) ON DELETE CASCADE
+);
+
+ALTER TABLE access_token ADD COLUMN act TEXT;
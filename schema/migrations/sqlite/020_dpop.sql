@@ -0,0 +1,8 @@
+ALTER TABLE client ADD COLUMN require_dpop BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE refresh_token ADD COLUMN jkt TEXT;
+ALTER TABLE access_token ADD COLUMN jkt TEXT;
+
+CREATE TABLE IF NOT EXISTS dpop_proof (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS used_jti (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,47 @@
+-- Deliveries queued before this migration do not know the user they
+-- belong to. Their logout tokens only identify the session.
+BEGIN TRANSACTION;
+
+CREATE TABLE backchannel_logout_outbox_new (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
+
+INSERT INTO backchannel_logout_outbox_new (
+    id,
+    client_id,
+    user_id,
+    session_id,
+    status,
+    attempts,
+    last_error,
+    next_attempt_at,
+    delivered_at,
+    created_at
+)
+SELECT
+    id,
+    client_id,
+    '',
+    session_id,
+    status,
+    attempts,
+    last_error,
+    next_attempt_at,
+    delivered_at,
+    created_at
+FROM backchannel_logout_outbox;
+
+DROP TABLE backchannel_logout_outbox;
+ALTER TABLE backchannel_logout_outbox_new RENAME TO backchannel_logout_outbox;
+
+COMMIT;
@@ -4,21 +4,65 @@ CREATE TABLE IF NOT EXISTS user (
     avatar_url VARCHAR(100),
     hashed_password VARCHAR(255),
     is_admin BOOLEAN NOT NULL DEFAULT false,
+    email_verified BOOLEAN NOT NULL DEFAULT false,
+    name VARCHAR(100),
+    given_name VARCHAR(50),
+    family_name VARCHAR(50),
+    phone_number VARCHAR(20),
+    street_address VARCHAR(255),
+    locality VARCHAR(50),
+    region VARCHAR(50),
+    postal_code VARCHAR(20),
+    country VARCHAR(50),
+    updated_at TIMESTAMP NULL,
     created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS client (
     id CHAR(25) PRIMARY KEY,
-    secret_hash CHAR(97) NOT NULL,
+    secret_hash CHAR(97),
     name VARCHAR(50) NOT NULL UNIQUE,
     picture_url VARCHAR(100),
     auth_callback_urls VARCHAR(1000) NOT NULL,
     logout_callback_urls VARCHAR(1000) NOT NULL,
     backchannel_logout_url VARCHAR(100),
     token_expiration bigint NOT NULL DEFAULT 28800,
+    client_type VARCHAR(12) NOT NULL DEFAULT 'confidential',
+    allowed_scopes VARCHAR(255),
+    token_endpoint_auth_method VARCHAR(30) NOT NULL DEFAULT 'client_secret_post',
+    jwks TEXT,
+    jwks_uri VARCHAR(100),
+    require_signed_request_object BOOLEAN NOT NULL DEFAULT false,
+    require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT false,
+    registration_access_token_hash CHAR(64),
+    id_token_signed_response_alg VARCHAR(10) NOT NULL DEFAULT 'EdDSA',
+    frontchannel_logout_uri VARCHAR(100),
+    userinfo_signed_response_alg VARCHAR(10),
+    access_token_format VARCHAR(10) NOT NULL DEFAULT 'jwt',
+    require_dpop BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
+CREATE TABLE IF NOT EXISTS api_resource (
+    id CHAR(25) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    identifier VARCHAR(100) NOT NULL UNIQUE,
+    scopes VARCHAR(255),
+    token_expiration BIGINT NOT NULL DEFAULT 1800,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS token_exchange_policy (
+    id CHAR(25) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    api_resource_id CHAR(25) NOT NULL,
+    scopes VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, api_resource_id),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (api_resource_id) REFERENCES api_resource(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS authorization_history (
     user_id CHAR(25) NOT NULL,
     client_id CHAR(25) NOT NULL,
@@ -36,22 +80,108 @@ CREATE TABLE IF NOT EXISTS session (
     expires_at TIMESTAMP NOT NULL,
     os VARCHAR(15),
     browser VARCHAR(50),
+    auth_time TIMESTAMP NULL,
+    parent_id CHAR(25),
     FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
-    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES session(id) ON DELETE SET NULL
 );
 
 CREATE TABLE IF NOT EXISTS authorization_code (
     id CHAR(13) PRIMARY KEY,
     user_id CHAR(25) NOT NULL,
     client_id CHAR(25) NOT NULL,
-    scopes VARCHAR(50) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    code_challenge VARCHAR(128),
+    code_challenge_method VARCHAR(5),
+    nonce VARCHAR(255),
+    auth_time TIMESTAMP NULL,
     os VARCHAR(15),
     browser VARCHAR(50),
     expires_at TIMESTAMP,
+    session_id CHAR(25),
+    resource VARCHAR(100),
     FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
     FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id CHAR(64) PRIMARY KEY,
+    session_id CHAR(25) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    resource VARCHAR(100),
+    jkt CHAR(43),
+    used BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS access_token (
+    id CHAR(64) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    user_id CHAR(25),
+    session_id CHAR(25),
+    subject VARCHAR(255) NOT NULL,
+    audience VARCHAR(100) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    act VARCHAR(1000),
+    jkt CHAR(43),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS device_code (
+    id CHAR(64) PRIMARY KEY,
+    user_code CHAR(8) NOT NULL UNIQUE,
+    client_id CHAR(25) NOT NULL,
+    user_id CHAR(25),
+    scopes VARCHAR(255) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    poll_interval BIGINT NOT NULL DEFAULT 5,
+    last_polled_at TIMESTAMP NULL,
+    auth_time TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS used_jti (
+    id CHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pushed_authorization_request (
+    id CHAR(64) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    params TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS dpop_proof (
+    id CHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS backchannel_logout_outbox (
+    id CHAR(25) PRIMARY KEY,
+    client_id CHAR(25) NOT NULL,
+    user_id CHAR(25) NOT NULL,
+    session_id CHAR(25) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error VARCHAR(255),
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
+
 -- CREATE EVENT delete_expired_sessions
 -- ON SCHEDULE EVERY 30 MINUTE
 -- STARTS CURRENT_TIMESTAMP
@@ -36,7 +36,9 @@ WHERE id = ? LIMIT 1;
 -- name: GetSessionWithUser :one
 SELECT
     session.id,
+    session.created_at,
     session.expires_at,
+    session.auth_time,
     user.id as user_id,
     user.email,
     user.avatar_url,
@@ -53,10 +55,12 @@ WHERE
 -- name: GetSessionWithClient :one
 SELECT
     session.id,
+    session.parent_id,
     client.id as client_id,
     client.name as client_name,
     client.logout_callback_urls,
-    client.backchannel_logout_url
+    client.backchannel_logout_url,
+    client.frontchannel_logout_uri
 FROM
     session
 INNER JOIN
@@ -69,10 +73,12 @@ WHERE
 -- name: GetSessionWithOptionalClient :one
 SELECT
     session.id,
+    session.user_id,
     client.id as client_id,
     client.name as client_name,
     client.logout_callback_urls,
-    client.backchannel_logout_url
+    client.backchannel_logout_url,
+    client.frontchannel_logout_uri
 FROM
     session
 LEFT JOIN
@@ -99,6 +105,22 @@ ON
 WHERE
     session.user_id = ?;
 
+-- name: GetChildSessionsWithClient :many
+SELECT
+    session.id,
+    session.user_id,
+    client.id as client_id,
+    client.backchannel_logout_url,
+    client.frontchannel_logout_uri
+FROM
+    session
+INNER JOIN
+    client
+ON
+    session.client_id = client.id
+WHERE
+    session.parent_id = ?;
+
 -- name: GetAuthzHistory :one
 SELECT * FROM authorization_history
 WHERE user_id = ? AND client_id = ?;
@@ -107,10 +129,120 @@ WHERE user_id = ? AND client_id = ?;
 SELECT * FROM authorization_code
 WHERE id = ?;
 
+-- name: GetRefreshTokenWithSession :one
+SELECT
+    refresh_token.id,
+    refresh_token.session_id,
+    refresh_token.scopes,
+    refresh_token.resource,
+    refresh_token.jkt,
+    refresh_token.used,
+    refresh_token.expires_at,
+    session.user_id,
+    session.client_id,
+    session.auth_time,
+    session.created_at
+FROM
+    refresh_token
+INNER JOIN
+    session
+ON
+    refresh_token.session_id = session.id
+WHERE
+    refresh_token.id = ?;
+
+-- name: GetAccessToken :one
+SELECT * FROM access_token
+WHERE id = ?;
+
+-- name: GetDeviceCode :one
+SELECT * FROM device_code
+WHERE id = ?;
+
+-- name: GetDeviceCodeByUserCode :one
+SELECT * FROM device_code
+WHERE user_code = ?;
+
+-- name: GetPushedAuthzRequest :one
+SELECT * FROM pushed_authorization_request
+WHERE id = ?;
+
+-- name: GetDueBackchannelLogouts :many
+SELECT
+    backchannel_logout_outbox.id,
+    backchannel_logout_outbox.client_id,
+    backchannel_logout_outbox.user_id,
+    backchannel_logout_outbox.session_id,
+    backchannel_logout_outbox.attempts,
+    client.backchannel_logout_url,
+    client.id_token_signed_response_alg
+FROM
+    backchannel_logout_outbox
+INNER JOIN
+    client
+ON
+    backchannel_logout_outbox.client_id = client.id
+WHERE
+    backchannel_logout_outbox.status = 'pending'
+    AND backchannel_logout_outbox.next_attempt_at <= ?
+ORDER BY
+    backchannel_logout_outbox.next_attempt_at
+LIMIT 25;
+
+-- name: GetBackchannelLogoutsForClientID :many
+SELECT * FROM backchannel_logout_outbox
+WHERE client_id = ?
+ORDER BY created_at DESC
+LIMIT 25;
+
+-- name: GetAPIResources :many
+SELECT * FROM api_resource;
+
+-- name: GetAPIResource :one
+SELECT * FROM api_resource
+WHERE id = ?;
+
+-- name: GetAPIResourceByName :one
+SELECT * FROM api_resource
+WHERE name = ?;
+
+-- name: GetAPIResourceByIdentifier :one
+SELECT * FROM api_resource
+WHERE identifier = ?;
+
+-- name: GetTokenExchangePolicy :one
+SELECT * FROM token_exchange_policy
+WHERE client_id = ? AND api_resource_id = ?;
+
+-- name: GetTokenExchangePoliciesForClientID :many
+SELECT
+    token_exchange_policy.id,
+    token_exchange_policy.scopes,
+    token_exchange_policy.created_at,
+    api_resource.name as api_resource_name,
+    api_resource.identifier as api_resource_identifier
+FROM
+    token_exchange_policy
+INNER JOIN
+    api_resource
+ON
+    token_exchange_policy.api_resource_id = api_resource.id
+WHERE
+    token_exchange_policy.client_id = ?
+ORDER BY
+    token_exchange_policy.created_at;
+
 
 -- name: CreateUser :execresult
-INSERT INTO user (id, email, hashed_password, avatar_url) VALUES (
-    ?, ?, ?, ?
+INSERT INTO user (
+    id,
+    email,
+    hashed_password,
+    avatar_url,
+    email_verified,
+    name
+) VALUES (
+    ?, ?, ?, ?, ?, ?
 );
 
 -- name: CreateClient :execresult
@@ -122,9 +254,22 @@ INSERT INTO client (
     logout_callback_urls,
     picture_url,
     backchannel_logout_url,
-    token_expiration
+    token_expiration,
+    client_type,
+    allowed_scopes,
+    token_endpoint_auth_method,
+    jwks,
+    jwks_uri,
+    require_signed_request_object,
+    require_pushed_authorization_requests,
+    registration_access_token_hash,
+    id_token_signed_response_alg,
+    frontchannel_logout_uri,
+    userinfo_signed_response_alg,
+    access_token_format,
+    require_dpop
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 -- name: CreateSession :execresult
@@ -134,9 +279,11 @@ INSERT INTO session (
     client_id,
     expires_at,
     os,
-    browser
+    browser,
+    auth_time,
+    parent_id
 ) VALUES (
-    ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 -- name: CreateAuthzHistory :execresult
@@ -148,11 +295,114 @@ INSERT INTO authorization_code (
     user_id,
     client_id,
     scopes,
+    code_challenge,
+    code_challenge_method,
+    nonce,
+    auth_time,
     os,
     browser,
+    expires_at,
+    session_id,
+    resource
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: CreateRefreshToken :execresult
+INSERT INTO refresh_token (
+    id,
+    session_id,
+    scopes,
+    resource,
+    jkt,
     expires_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?
+);
+
+-- name: CreateAccessToken :execresult
+INSERT INTO access_token (
+    id,
+    client_id,
+    user_id,
+    session_id,
+    subject,
+    audience,
+    scopes,
+    act,
+    jkt,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: CreateDPoPProof :execresult
+INSERT INTO dpop_proof (
+    id,
+    expires_at
+) VALUES (
+    ?, ?
+);
+
+-- name: CreateDeviceCode :execresult
+INSERT INTO device_code (
+    id,
+    user_code,
+    client_id,
+    scopes,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: CreateUsedJTI :execresult
+INSERT INTO used_jti (
+    id,
+    expires_at
+) VALUES (
+    ?, ?
+);
+
+-- name: CreatePushedAuthzRequest :execresult
+INSERT INTO pushed_authorization_request (
+    id,
+    client_id,
+    params,
+    expires_at
+) VALUES (
+    ?, ?, ?, ?
+);
+
+-- name: CreateBackchannelLogout :execresult
+INSERT INTO backchannel_logout_outbox (
+    id,
+    client_id,
+    user_id,
+    session_id,
+    next_attempt_at
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: CreateAPIResource :execresult
+INSERT INTO api_resource (
+    id,
+    name,
+    identifier,
+    scopes,
+    token_expiration
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: CreateTokenExchangePolicy :execresult
+INSERT INTO token_exchange_policy (
+    id,
+    client_id,
+    api_resource_id,
+    scopes
+) VALUES (
+    ?, ?, ?, ?
 );
 
 
@@ -166,6 +416,20 @@ UPDATE user
 SET avatar_url = ?
 WHERE id = ?;
 
+-- name: UpdateUserProfile :exec
+UPDATE user
+SET name = ?,
+    given_name = ?,
+    family_name = ?,
+    phone_number = ?,
+    street_address = ?,
+    locality = ?,
+    region = ?,
+    postal_code = ?,
+    country = ?,
+    updated_at = ?
+WHERE id = ?;
+
 -- name: UpdateClient :exec
 UPDATE client
 SET name = ?,
@@ -173,6 +437,62 @@ SET name = ?,
     logout_callback_urls = ?,
     picture_url = ?,
     backchannel_logout_url = ?,
+    token_expiration = ?,
+    allowed_scopes = ?,
+    token_endpoint_auth_method = ?,
+    jwks = ?,
+    jwks_uri = ?,
+    require_signed_request_object = ?,
+    require_pushed_authorization_requests = ?,
+    id_token_signed_response_alg = ?,
+    frontchannel_logout_uri = ?,
+    userinfo_signed_response_alg = ?,
+    access_token_format = ?,
+    require_dpop = ?
+WHERE id = ?;
+
+-- name: UpdateSessionExpiry :exec
+UPDATE session
+SET expires_at = ?
+WHERE id = ?;
+
+-- name: UpdateAuthzHistory :exec
+UPDATE authorization_history
+SET authorized_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND client_id = ?;
+
+-- name: MarkRefreshTokenUsed :execresult
+UPDATE refresh_token
+SET used = true
+WHERE id = ? AND used = false;
+
+-- name: UpdateDeviceCodeStatus :execresult
+UPDATE device_code
+SET status = ?,
+    user_id = ?,
+    auth_time = ?
+WHERE user_code = ? AND status = 'pending';
+
+-- name: UpdateDeviceCodePoll :exec
+UPDATE device_code
+SET poll_interval = ?,
+    last_polled_at = ?
+WHERE id = ?;
+
+-- name: UpdateBackchannelLogout :exec
+UPDATE backchannel_logout_outbox
+SET status = ?,
+    attempts = ?,
+    last_error = ?,
+    next_attempt_at = ?,
+    delivered_at = ?
+WHERE id = ?;
+
+-- name: UpdateAPIResource :exec
+UPDATE api_resource
+SET name = ?,
+    identifier = ?,
+    scopes = ?,
     token_expiration = ?
 WHERE id = ?;
 
@@ -181,18 +501,70 @@ WHERE id = ?;
 DELETE FROM client
 WHERE id = ?;
 
+-- name: DeleteAPIResource :exec
+DELETE FROM api_resource
+WHERE id = ?;
+
+-- name: DeleteTokenExchangePolicy :exec
+DELETE FROM token_exchange_policy
+WHERE id = ? AND client_id = ?;
+
 -- name: DeleteSession :exec
 DELETE FROM session
 WHERE id = ?;
 
+-- name: DeleteChildSessions :exec
+DELETE FROM session
+WHERE parent_id = ?;
+
 -- name: DeleteExpiredSessions :exec
 DELETE FROM session
 WHERE expires_at <= CURRENT_TIMESTAMP;
 
--- name: DeleteAuthzCode :exec
+-- name: DeleteAuthzCode :execresult
 DELETE FROM authorization_code
 WHERE id = ?;
 
 -- name: DeleteExpiredAuthzCode :exec
 DELETE FROM authorization_code
 WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredRefreshTokens :exec
+DELETE FROM refresh_token
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteAccessToken :exec
+DELETE FROM access_token
+WHERE id = ?;
+
+-- name: DeleteExpiredAccessTokens :exec
+DELETE FROM access_token
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredDPoPProofs :exec
+DELETE FROM dpop_proof
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteDeviceCode :execresult
+DELETE FROM device_code
+WHERE id = ?;
+
+-- name: DeleteExpiredDeviceCodes :exec
+DELETE FROM device_code
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredUsedJTIs :exec
+DELETE FROM used_jti
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeletePushedAuthzRequest :execresult
+DELETE FROM pushed_authorization_request
+WHERE id = ?;
+
+-- name: DeleteExpiredPushedAuthzRequests :exec
+DELETE FROM pushed_authorization_request
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteStaleBackchannelLogouts :exec
+DELETE FROM backchannel_logout_outbox
+WHERE status != 'pending' AND created_at <= ?;
@@ -4,21 +4,65 @@ CREATE TABLE IF NOT EXISTS user (
     avatar_url TEXT,
     hashed_password TEXT,
     is_admin BOOLEAN NOT NULL DEFAULT false,
+    email_verified BOOLEAN NOT NULL DEFAULT false,
+    name TEXT,
+    given_name TEXT,
+    family_name TEXT,
+    phone_number TEXT,
+    street_address TEXT,
+    locality TEXT,
+    region TEXT,
+    postal_code TEXT,
+    country TEXT,
+    updated_at TIMESTAMP,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS client (
     id TEXT PRIMARY KEY,
-    secret_hash TEXT NOT NULL,
+    secret_hash TEXT,
     name TEXT NOT NULL UNIQUE,
     picture_url TEXT,
     auth_callback_urls TEXT NOT NULL,
     logout_callback_urls TEXT NOT NULL,
     backchannel_logout_url TEXT,
     token_expiration bigint NOT NULL DEFAULT 28800,
+    client_type TEXT NOT NULL DEFAULT 'confidential',
+    allowed_scopes TEXT,
+    token_endpoint_auth_method TEXT NOT NULL DEFAULT 'client_secret_post',
+    jwks TEXT,
+    jwks_uri TEXT,
+    require_signed_request_object BOOLEAN NOT NULL DEFAULT false,
+    require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT false,
+    registration_access_token_hash TEXT,
+    id_token_signed_response_alg TEXT NOT NULL DEFAULT 'EdDSA',
+    frontchannel_logout_uri TEXT,
+    userinfo_signed_response_alg TEXT,
+    access_token_format TEXT NOT NULL DEFAULT 'jwt',
+    require_dpop BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE IF NOT EXISTS api_resource (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    identifier TEXT NOT NULL UNIQUE,
+    scopes TEXT,
+    token_expiration bigint NOT NULL DEFAULT 1800,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS token_exchange_policy (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    api_resource_id TEXT NOT NULL,
+    scopes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (client_id, api_resource_id),
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (api_resource_id) REFERENCES api_resource(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS authorization_history (
     user_id TEXT NOT NULL,
     client_id TEXT NOT NULL,
@@ -36,8 +80,11 @@ CREATE TABLE IF NOT EXISTS session (
     expires_at TIMESTAMP NOT NULL,
     os TEXT,
     browser TEXT,
+    auth_time TIMESTAMP,
+    parent_id TEXT,
     FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
-    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES session(id) ON DELETE SET NULL
 );
 
 CREATE TABLE IF NOT EXISTS authorization_code (
@@ -45,13 +92,96 @@ CREATE TABLE IF NOT EXISTS authorization_code (
     user_id TEXT NOT NULL,
     client_id TEXT NOT NULL,
     scopes TEXT NOT NULL,
+    code_challenge TEXT,
+    code_challenge_method TEXT,
+    nonce TEXT,
+    auth_time TIMESTAMP,
     os TEXT,
     browser TEXT,
     expires_at TIMESTAMP,
+    session_id TEXT,
+    resource TEXT,
     FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
     FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    resource TEXT,
+    jkt TEXT,
+    used BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS access_token (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    user_id TEXT,
+    session_id TEXT,
+    subject TEXT NOT NULL,
+    audience TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    act TEXT,
+    jkt TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES session(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS device_code (
+    id TEXT PRIMARY KEY,
+    user_code TEXT NOT NULL UNIQUE,
+    client_id TEXT NOT NULL,
+    user_id TEXT,
+    scopes TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    poll_interval bigint NOT NULL DEFAULT 5,
+    last_polled_at TIMESTAMP,
+    auth_time TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS used_jti (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pushed_authorization_request (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    params TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS dpop_proof (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS backchannel_logout_outbox (
+    id TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (client_id) REFERENCES client(id) ON DELETE CASCADE
+);
+
 -- CREATE EVENT delete_expired_sessions
 -- ON SCHEDULE EVERY 30 MINUTE
 -- STARTS CURRENT_TIMESTAMP
@@ -7,13 +7,52 @@ import "github.com/golang-jwt/jwt/v5"
 type IDTokenClaims struct {
 	jwt.RegisteredClaims
 	SID string `json:"sid"`
+	// Nonce is the value passed in the authorization request, if any.
+	Nonce string `json:"nonce,omitempty"`
+	// AuthTime is the time when the user authenticated.
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// AtHash is the access token hash. It is the base64url encoded left
+	// half of the access token digest, using SHA-512 for EdDSA and
+	// SHA-256 for RS256 and ES256 signed tokens.
+	AtHash string `json:"at_hash,omitempty"`
+	UserClaims
 }
 
 // UserInfoClaims represents the user's information returned from
 // Ellipsis' user info endpoint.
 type UserInfoClaims struct {
-	Email     string `json:"email"`
-	AvatarURL string `json:"avatar_url"`
+	Sub string `json:"sub"`
+	UserClaims
+}
+
+// UserClaims are the standard claims describing the user. Each claim is
+// only present if the scope releasing it was granted:
+//
+//   - profile: name, given_name, family_name, picture and updated_at
+//   - email: email and email_verified
+//   - phone: phone_number and phone_number_verified
+//   - address: address
+type UserClaims struct {
+	Name                string        `json:"name,omitempty"`
+	GivenName           string        `json:"given_name,omitempty"`
+	FamilyName          string        `json:"family_name,omitempty"`
+	Picture             string        `json:"picture,omitempty"`
+	UpdatedAt           int64         `json:"updated_at,omitempty"`
+	Email               string        `json:"email,omitempty"`
+	EmailVerified       bool          `json:"email_verified,omitempty"`
+	PhoneNumber         string        `json:"phone_number,omitempty"`
+	PhoneNumberVerified bool          `json:"phone_number_verified,omitempty"`
+	Address             *AddressClaim `json:"address,omitempty"`
+}
+
+// AddressClaim represents the user's postal address.
+type AddressClaim struct {
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"street_address,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Country       string `json:"country,omitempty"`
 }
 
 // LogoutTokenClaims represents the claims within the logout token
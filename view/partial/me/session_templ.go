@@ -272,57 +272,7 @@ func DeleteSessionUnsupportedBackchannelLogout(clientName, sid string) templ.Com
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"hidden\"> <input type=\"checkbox\" name=\"force\" checked value=\"1\" class=\"hidden\"> <a class=\"btn w-full lg:w-fit\" href=\"/session\">Cancel</a> <button class=\"btn btn-error w-full lg:w-fit\" type=\"submit\">Revoke Anyway <span id=\"spinner\" class=\"ml-1 hidden loading loading-spinner\"></span></button></form></main></div>")
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		if !templ_7745c5c3_IsBuffer {
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)
-		}
-		return templ_7745c5c3_Err
-	})
-}
-
-func DeleteSessionBackchannelLogoutFailure(clientName, sid string) templ.Component {
-	return templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {
-		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templ_7745c5c3_W.(*bytes.Buffer)
-		if !templ_7745c5c3_IsBuffer {
-			templ_7745c5c3_Buffer = templ.GetBuffer()
-			defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)
-		}
-		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var15 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var15 == nil {
-			templ_7745c5c3_Var15 = templ.NopComponent
-		}
-		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div class=\"flex h-screen overflow-hidden\"><aside class=\"hidden h-screen w-full bg-temple lg:block\"></aside><main class=\"mx-3 flex w-full flex-col items-center justify-center\"><h1 class=\"mb-5 text-center text-4xl font-bold\">Back-Channel Logout Failed</h1><p class=\"text-center mb-10\">Revoking session may <strong>not</strong> sign you out of ")
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		var templ_7745c5c3_Var16 string
-		templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(clientName)
-		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/partial/me/session.templ`, Line: 139, Col: 74}
-		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</p><form class=\"w-full flex flex-col-reverse justify-center space-y-2 lg:flex-row lg:space-y-2 lg:space-x-4\" hx-boost=\"true\" hx-indicator=\"#spinner\" method=\"post\" action=\"/session/delete\"><input type=\"text\" name=\"id\" value=\"")
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		var templ_7745c5c3_Var17 string
-		templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinStringErrs(sid)
-		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `view/partial/me/session.templ`, Line: 148, Col: 44}
-		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"hidden\"> <input type=\"checkbox\" name=\"force\" checked value=\"1\" class=\"hidden\"> <a class=\"btn w-full lg:w-fit\" href=\"/session\">Cancel</a> <button class=\"btn btn-error w-full lg:w-fit\" type=\"submit\">Revoke Anyway <span id=\"spinner\" class=\"ml-1 hidden loading loading-spinner\"></span></button></form></main></div>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"hidden\"> <a class=\"btn w-full lg:w-fit\" href=\"/session\">Cancel</a> <button class=\"btn btn-error w-full lg:w-fit\" type=\"submit\">Revoke Anyway <span id=\"spinner\" class=\"ml-1 hidden loading loading-spinner\"></span></button></form></main></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}